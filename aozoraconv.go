@@ -33,23 +33,10 @@ func reverse(s []string) []string {
 	return r
 }
 
-// Conv replaces some characters in Unicode
+// Conv replaces some characters in Unicode,
+// errors of escapers (e.g. *NotationError by WithStrictNotation) are returned with the position
 func Conv(w io.Writer, r io.Reader, opts ...OptionFunc) error {
-	option := newOption(opts...)
-	esc := NewEscape(option)
-
-	scan := NewAozoraTextScanner(r)
-	for scan.Scan() {
-		replaced := aozoraUtf8CharReplacer.Replace(scan.Text())
-		newText, ok := esc.Escape(replaced)
-		if ok != true {
-			continue
-		}
-		if _, err := w.Write([]byte(newText)); err != nil {
-			return errors.WithStack(err)
-		}
-	}
-	if err := scan.Err(); err != nil {
+	if _, err := io.Copy(w, NewReader(r, opts...)); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
@@ -57,28 +44,13 @@ func Conv(w io.Writer, r io.Reader, opts ...OptionFunc) error {
 
 // ConvRev replaces some characters in Unicode
 func ConvRev(w io.Writer, r io.Reader, opts ...OptionFunc) error {
-	option := newOption(opts...)
-	esc := NewEscape(option)
-
-	scan := NewAozoraTextScanner(r)
-	for scan.Scan() {
-		text := scan.Text()
-		newText, ok := esc.Escape(text)
-		if ok != true {
-			continue
-		}
-		replaced := aozoraUtf8CharReplacerR.Replace(newText)
-		if _, err := w.Write([]byte(replaced)); err != nil {
-			return errors.WithStack(err)
-		}
-	}
-	if err := scan.Err(); err != nil {
+	if _, err := io.Copy(w, NewRevReader(r, opts...)); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-// Decode convert from UTF-8 into Aozora Bunko format (Shift_JIS)
+// Decode convert from Aozora Bunko format (Shift_JIS) into UTF-8
 func Decode(output io.Writer, input io.Reader, opts ...OptionFunc) (err error) {
 	decoder := japanese.ShiftJIS.NewDecoder()
 	reader := transform.NewReader(input, decoder)
@@ -88,7 +60,9 @@ func Decode(output io.Writer, input io.Reader, opts ...OptionFunc) (err error) {
 	return nil
 }
 
-// Encode convert from Aozora Bunko format (Shift_JIS) into UTF-8
+// Encode convert from UTF-8 into Aozora Bunko format (Shift_JIS),
+// CJK compatibility ideographs and variation sequences which are not in Shift_JIS are errors
+// unless WithCompatIdeograph or WithIVS is given
 func Encode(output io.Writer, input io.Reader, opts ...OptionFunc) (err error) {
 	encoder := japanese.ShiftJIS.NewEncoder()
 	writer := transform.NewWriter(output, encoder)
@@ -98,6 +72,30 @@ func Encode(output io.Writer, input io.Reader, opts ...OptionFunc) (err error) {
 	return nil
 }
 
+func withLastEscapers(opts []OptionFunc, escapers ...Escaper) []OptionFunc {
+	funcs := make([]OptionFunc, 0, len(opts)+1)
+	funcs = append(funcs, opts...)
+	return append(funcs, WithEscapers(escapers...))
+}
+
+// Decode2004 convert from Shift_JIS-2004 into UTF-8, characters of JIS X 0213
+// (e.g. か゚ 1-4-87) are decoded without replacement of Conv
+func Decode2004(output io.Writer, input io.Reader, opts ...OptionFunc) error {
+	if _, err := io.Copy(output, NewDecode2004Reader(input, opts...)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Encode2004 convert from UTF-8 into Shift_JIS-2004, combining character sequences
+// (か + U+309A) are encoded into one character and variation selectors are removed
+func Encode2004(output io.Writer, input io.Reader, opts ...OptionFunc) error {
+	if _, err := io.Copy(output, NewEncode2004Reader(input, opts...)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 // Jis2Uni returns a string from jis codepoint
 func Jis2Uni(men, ku, ten int) (str string, err error) {
 	if men < 1 || men > 2 || ku < 1 || ku > 94 || ten < 1 || ten > 94 {
@@ -110,53 +108,71 @@ func Jis2Uni(men, ku, ten int) (str string, err error) {
 	return chr, nil
 }
 
-// Uni2Jis returns a pointer of JisEntry
+// Uni2Jis returns a pointer of JisEntry,
+// canonical equivalents of CJK compatibility ideographs are looked up if the character is not in JIS X 0213,
+// variation sequences are the compatibility ideograph of the variant or the base character
 func Uni2Jis(str string) (jis JisEntry, err error) {
-	var s1 uint16
 	r := []rune(str)
+	if len(r) == 0 {
+		return JisEntry{0, 0, 0}, errors.Errorf("empty string")
+	}
 	r1 := r[0]
 	if len(r) == 1 {
-		switch {
-		case 0x20 <= r1 && r1 < 0x7f:
+		if 0x20 <= r1 && r1 < 0x7f {
 			return JisEntry{0, 0, 0}, errors.Errorf("ASCII character")
-		case encode0Low <= r1 && r1 < encode0High:
-			if s1 = encode0[r1-encode0Low]; (s1>>planeShift)&0x0003 > 0 {
-				goto write2
-			}
-		case encode1Low <= r1 && r1 < encode1High:
-			if s1 = encode1[r1-encode1Low]; (s1>>planeShift)&0x0003 > 0 {
-				goto write2
-			}
-		case encode2Low <= r1 && r1 < encode2High:
-			if s1 = encode2[r1-encode2Low]; (s1>>planeShift)&0x0003 > 0 {
-				goto write2
-			}
-		case encode3Low <= r1 && r1 < encode3High:
-			if s1 = encode3[r1-encode3Low]; (s1>>planeShift)&0x0003 > 0 {
-				goto write2
-			}
-		case encode4Low <= r1 && r1 < encode4High:
-			if s1 = encode4[r1-encode4Low]; (s1>>planeShift)&0x0003 > 0 {
-				goto write2
+		}
+		if entry, ok := lookupJis(r1); ok {
+			return entry, nil
+		}
+		for _, eq := range canonicalEquivalents(r1) {
+			if entry, ok := lookupJis(eq); ok {
+				return entry, nil
 			}
 		}
 		return JisEntry{0, 0, 0}, errors.Errorf("invalid character")
-	write2:
-		men := int8(s1 >> planeShift)
-		ku := int8((s1 >> codeShift) & codeMask)
-		ten := int8((s1) & codeMask)
-		return JisEntry{men: men, ku: ku, ten: ten}, nil
 	} else if len(r) == 2 {
 		r2 := r[1]
+		if isVariationSelector(r2) {
+			if c, ok := variantIdeograph(r1, r2); ok {
+				if entry, ok := lookupJis(c); ok {
+					return entry, nil
+				}
+			}
+			return Uni2Jis(string(r1))
+		}
 		entry, ok := multichars[r1][r2]
 		if !ok {
-			return JisEntry{0, 0, 0}, err
+			return JisEntry{0, 0, 0}, errors.Errorf("invalid combining character sequence: %U %U", r1, r2)
 		}
 		return entry, nil
 	}
 	return JisEntry{0, 0, 0}, errors.Errorf("length of string should be 1 or 2")
 }
 
+// lookupJis returns JisEntry of the character in JIS X 0213
+func lookupJis(r1 rune) (JisEntry, bool) {
+	var s1 uint16
+	switch {
+	case encode0Low <= r1 && r1 < encode0High:
+		s1 = encode0[r1-encode0Low]
+	case encode1Low <= r1 && r1 < encode1High:
+		s1 = encode1[r1-encode1Low]
+	case encode2Low <= r1 && r1 < encode2High:
+		s1 = encode2[r1-encode2Low]
+	case encode3Low <= r1 && r1 < encode3High:
+		s1 = encode3[r1-encode3Low]
+	case encode4Low <= r1 && r1 < encode4High:
+		s1 = encode4[r1-encode4Low]
+	}
+	if (s1>>planeShift)&0x0003 < 1 {
+		return JisEntry{0, 0, 0}, false
+	}
+	men := int8(s1 >> planeShift)
+	ku := int8((s1 >> codeShift) & codeMask)
+	ten := int8((s1) & codeMask)
+	return JisEntry{Men: men, Ku: ku, Ten: ten}, true
+}
+
 // Is0208 checks triplet men-ku-ten is in JIS X 0208 or not
 func Is0208(men, ku, ten int) bool {
 	if men != 1 {
@@ -220,3 +236,94 @@ func Kuten2Sjis(ku, ten int) []byte {
 	}
 	return []byte{uint8(s1), uint8(s2)}
 }
+
+// MenKuTen2Sjis2004 returns Shift_JIS-2004 byte strings (2 byte) from men-ku-ten code
+func MenKuTen2Sjis2004(men, ku, ten int) ([]byte, error) {
+	if men < 1 || men > 2 || ku < 1 || ku > 94 || ten < 1 || ten > 94 {
+		return nil, errors.Errorf("error: args should be in 1..2, 1..94, 1..94")
+	}
+
+	var s1, s2 int
+	if men == 1 {
+		if ku <= 62 {
+			s1 = (ku + 0x101) / 2
+		} else {
+			s1 = (ku + 0x181) / 2
+		}
+	} else {
+		switch {
+		case ku == 1, ku == 3, ku == 4, ku == 5, ku == 8, 12 <= ku && ku <= 15:
+			s1 = (ku+0x1df)/2 - (ku/8)*3
+		case 78 <= ku:
+			s1 = (ku + 0x19b) / 2
+		default:
+			return nil, errors.Errorf("invalid access men: %v ku:%v ten:%v, not assigned in Shift_JIS-2004", men, ku, ten)
+		}
+	}
+
+	if ku%2 == 1 {
+		if ten <= 63 {
+			s2 = ten + 0x3f
+		} else {
+			s2 = ten + 0x40
+		}
+	} else {
+		s2 = ten + 0x9e
+	}
+	return []byte{uint8(s1), uint8(s2)}, nil
+}
+
+// sjis2004Plane2Ku is pair of ku (odd, even) for Shift_JIS-2004 plane 2 lead bytes 0xF0..0xF4
+var sjis2004Plane2Ku = [5][2]int{
+	{1, 8},
+	{3, 4},
+	{5, 12},
+	{13, 14},
+	{15, 78},
+}
+
+// Sjis2Kuten returns men-ku-ten code from Shift_JIS-2004 byte strings (2 byte)
+func Sjis2Kuten(b []byte) (men, ku, ten int, err error) {
+	if len(b) != 2 {
+		return 0, 0, 0, errors.Errorf("length of bytes should be 2")
+	}
+	s1, s2 := int(b[0]), int(b[1])
+	if s2 < 0x40 || s2 == 0x7f || 0xfc < s2 {
+		return 0, 0, 0, errors.Errorf("invalid trail byte: 0x%02X", s2)
+	}
+
+	var odd, even int
+	switch {
+	case 0x81 <= s1 && s1 <= 0x9f:
+		men = 1
+		odd = (s1-0x81)*2 + 1
+		even = odd + 1
+	case 0xe0 <= s1 && s1 <= 0xef:
+		men = 1
+		odd = (s1-0xe0)*2 + 63
+		even = odd + 1
+	case 0xf0 <= s1 && s1 <= 0xf4:
+		men = 2
+		odd = sjis2004Plane2Ku[s1-0xf0][0]
+		even = sjis2004Plane2Ku[s1-0xf0][1]
+	case 0xf5 <= s1 && s1 <= 0xfc:
+		men = 2
+		odd = (s1-0xf5)*2 + 79
+		even = odd + 1
+	default:
+		return 0, 0, 0, errors.Errorf("invalid lead byte: 0x%02X", s1)
+	}
+
+	switch {
+	case 0x9f <= s2:
+		ku = even
+		ten = s2 - 0x9e
+	case 0x80 <= s2:
+		ku = odd
+		ten = s2 - 0x40
+	default:
+		ku = odd
+		ten = s2 - 0x3f
+	}
+	return men, ku, ten, nil
+}
@@ -2,6 +2,7 @@ package aozoraconv
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -49,6 +50,48 @@ func TestConv(t *testing.T) {
 	}
 }
 
+func TestConvNotationError(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect NotationError
+	}{
+		{
+			in:     "一行目\r\n二行目｜停車場《ステーション\r\n",
+			expect: NotationError{Line: 2, Col: 8, Offset: 32, Kind: NotationUnclosedRuby},
+		},
+		{
+			in:     "一行目\r\n\r\n［＃７字下げ］本文［＃「二」は中見出し",
+			expect: NotationError{Line: 3, Col: 10, Offset: 40, Kind: NotationUnclosedAnnotation},
+		},
+		{
+			in:     "その※［＃「足へん＋宛」、第3水準1-99-99］",
+			expect: NotationError{Line: 1, Col: 3, Offset: 6, Kind: NotationInvalidGaiji},
+		},
+		{
+			in:     "ステーション》",
+			expect: NotationError{Line: 1, Col: 7, Offset: 18, Kind: NotationUnopenedRuby},
+		},
+		{
+			in:     "¢｜停車場《ステ",
+			expect: NotationError{Line: 1, Col: 6, Offset: 14, Kind: NotationUnclosedRuby},
+		},
+	}
+	for _, tc := range tests {
+		err := Conv(io.Discard, strings.NewReader(tc.in), WithStrictNotation())
+		var ne *NotationError
+		if errors.As(err, &ne) != true {
+			t.Fatalf("%s: expect NotationError actual=%v", tc.in, err)
+		}
+		if ne.Line != tc.expect.Line || ne.Col != tc.expect.Col || ne.Offset != tc.expect.Offset || ne.Kind != tc.expect.Kind {
+			t.Errorf("%s: expect=%+v actual=%+v", tc.in, tc.expect, *ne)
+		}
+	}
+
+	if err := Conv(io.Discard, strings.NewReader("晩｜停車場《ステーション》で［＃「で」に傍点］\r\n"), WithStrictNotation()); err != nil {
+		t.Errorf("no error: %+v", err)
+	}
+}
+
 func TestConvR(t *testing.T) {
 	var convertedStrings = []struct {
 		in  string
@@ -123,17 +166,18 @@ func TestSjisConv3(t *testing.T) {
 	}
 }
 
+var encodePairs = []struct {
+	in  string
+	out []byte
+}{
+	{"あいうえお", toSjis("あいうえお")},
+	{"\u301C", toSjis("\uFF5E")},
+	{"\uFF5E", toSjis("\uFF5E")},
+	{"¢", toSjis("￠")},
+}
+
 func TestEncode(t *testing.T) {
-	var convertedPairs = []struct {
-		in  string
-		out []byte
-	}{
-		{"あいうえお", toSjis("あいうえお")},
-		{"\u301C", toSjis("\uFF5E")},
-		{"\uFF5E", toSjis("\uFF5E")},
-		{"¢", toSjis("￠")},
-	}
-	for _, tt := range convertedPairs {
+	for _, tt := range encodePairs {
 		input := strings.NewReader(tt.in)
 		output := bytes.NewBuffer(nil)
 
@@ -146,16 +190,17 @@ func TestEncode(t *testing.T) {
 	}
 }
 
+var decodePairs = []struct {
+	out string
+	in  []byte
+}{
+	{"あいうえお", toSjis("あいうえお")},
+	{"\u301C", toSjis("\uFF5E")},
+	{"¢", toSjis("￠")},
+}
+
 func TestDecode(t *testing.T) {
-	var convertedPairs = []struct {
-		out string
-		in  []byte
-	}{
-		{"あいうえお", toSjis("あいうえお")},
-		{"\u301C", toSjis("\uFF5E")},
-		{"¢", toSjis("￠")},
-	}
-	for _, tt := range convertedPairs {
+	for _, tt := range decodePairs {
 		input := bytes.NewReader(tt.in)
 		output := bytes.NewBuffer(nil)
 
@@ -174,11 +219,19 @@ func TestUni2Jis(t *testing.T) {
 		out       JisEntry
 		isSuccess bool
 	}{
-		{"あ", JisEntry{men: 1, ku: 4, ten: 2}, true},
-		{"。", JisEntry{men: 1, ku: 1, ten: 3}, true},
-		{"◆", JisEntry{men: 1, ku: 2, ten: 1}, true},
+		{"あ", JisEntry{Men: 1, Ku: 4, Ten: 2}, true},
+		{"。", JisEntry{Men: 1, Ku: 1, Ten: 3}, true},
+		{"◆", JisEntry{Men: 1, Ku: 2, Ten: 1}, true},
 		{"A", JisEntry{0, 0, 0}, false},
 		{"☺", JisEntry{0, 0, 0}, false},
+		{"\uFA10", JisEntry{Men: 1, Ku: 15, Ten: 55}, true},
+		{"\u585A", JisEntry{Men: 1, Ku: 36, Ten: 45}, true},
+		{"\uF900", JisEntry{Men: 1, Ku: 76, Ten: 17}, true},
+		{"\u585A\uFE00", JisEntry{Men: 1, Ku: 15, Ten: 55}, true},
+		{"\u845B\U000E0101", JisEntry{Men: 1, Ku: 19, Ten: 75}, true},
+		{"\u304B\u309A", JisEntry{Men: 1, Ku: 4, Ten: 87}, true},
+		{"\u304B\u3099", JisEntry{0, 0, 0}, false},
+		{"", JisEntry{0, 0, 0}, false},
 	}
 	for _, tt := range convertedPairs {
 		got, err := Uni2Jis(tt.in)
@@ -339,3 +392,105 @@ func TestReverse(t *testing.T) {
 		t.Errorf("actual %v", v)
 	}
 }
+
+func TestMenKuTen2Sjis2004(t *testing.T) {
+	var convertedPairs = []struct {
+		men, ku, ten int
+		sjis         []byte
+		isSuccess    bool
+	}{
+		{1, 1, 1, []byte{0x81, 0x40}, true},
+		{1, 4, 2, []byte{0x82, 0xA0}, true},
+		{1, 47, 52, []byte{0x98, 0x73}, true},
+		{1, 63, 1, []byte{0xE0, 0x40}, true},
+		{1, 94, 94, []byte{0xEF, 0xFC}, true},
+		{2, 1, 1, []byte{0xF0, 0x40}, true},
+		{2, 8, 1, []byte{0xF0, 0x9F}, true},
+		{2, 3, 17, []byte{0xF1, 0x50}, true},
+		{2, 15, 94, []byte{0xF4, 0x9E}, true},
+		{2, 78, 1, []byte{0xF4, 0x9F}, true},
+		{2, 79, 1, []byte{0xF5, 0x40}, true},
+		{2, 94, 94, []byte{0xFC, 0xFC}, true},
+		{2, 2, 1, nil, false},
+		{2, 16, 1, nil, false},
+		{2, 77, 1, nil, false},
+		{3, 1, 1, nil, false},
+		{1, 0, 1, nil, false},
+		{1, 1, 95, nil, false},
+	}
+	for _, tt := range convertedPairs {
+		got, err := MenKuTen2Sjis2004(tt.men, tt.ku, tt.ten)
+		if err != nil && tt.isSuccess {
+			t.Errorf("MenKuTen2Sjis2004 %v-%v-%v; should not be error but %v", tt.men, tt.ku, tt.ten, err)
+		} else if err == nil && !tt.isSuccess {
+			t.Errorf("MenKuTen2Sjis2004 %v-%v-%v; should be error but got %v", tt.men, tt.ku, tt.ten, got)
+		}
+		if bytes.Equal(got, tt.sjis) != true {
+			t.Errorf("MenKuTen2Sjis2004 %v-%v-%v got: %X want: %X", tt.men, tt.ku, tt.ten, got, tt.sjis)
+		}
+	}
+}
+
+func TestSjis2Kuten(t *testing.T) {
+	var convertedPairs = []struct {
+		sjis         []byte
+		men, ku, ten int
+		isSuccess    bool
+	}{
+		{[]byte{0x81, 0x40}, 1, 1, 1, true},
+		{[]byte{0x82, 0xA0}, 1, 4, 2, true},
+		{[]byte{0x98, 0x73}, 1, 47, 52, true},
+		{[]byte{0xEF, 0xFC}, 1, 94, 94, true},
+		{[]byte{0xF0, 0x9F}, 2, 8, 1, true},
+		{[]byte{0xF4, 0x9F}, 2, 78, 1, true},
+		{[]byte{0xFC, 0xFC}, 2, 94, 94, true},
+		{[]byte{0x81}, 0, 0, 0, false},
+		{[]byte{0x81, 0x40, 0x40}, 0, 0, 0, false},
+		{[]byte{0x41, 0x40}, 0, 0, 0, false},
+		{[]byte{0xA0, 0x40}, 0, 0, 0, false},
+		{[]byte{0xFD, 0x40}, 0, 0, 0, false},
+		{[]byte{0x81, 0x3F}, 0, 0, 0, false},
+		{[]byte{0x81, 0x7F}, 0, 0, 0, false},
+		{[]byte{0x81, 0xFD}, 0, 0, 0, false},
+	}
+	for _, tt := range convertedPairs {
+		men, ku, ten, err := Sjis2Kuten(tt.sjis)
+		if err != nil && tt.isSuccess {
+			t.Errorf("Sjis2Kuten %X; should not be error but %v", tt.sjis, err)
+		} else if err == nil && !tt.isSuccess {
+			t.Errorf("Sjis2Kuten %X; should be error but got %v-%v-%v", tt.sjis, men, ku, ten)
+		}
+		if men != tt.men || ku != tt.ku || ten != tt.ten {
+			t.Errorf("Sjis2Kuten %X got: %v-%v-%v want: %v-%v-%v", tt.sjis, men, ku, ten, tt.men, tt.ku, tt.ten)
+		}
+	}
+}
+
+func TestAllMenKuTen2Sjis2004RoundTrip(t *testing.T) {
+	count := 0
+	for men := 1; men <= 2; men++ {
+		for ku := 1; ku <= 94; ku++ {
+			for ten := 1; ten <= 94; ten++ {
+				b, err := MenKuTen2Sjis2004(men, ku, ten)
+				if err != nil {
+					continue
+				}
+				count += 1
+				m, k, n, err := Sjis2Kuten(b)
+				if err != nil {
+					t.Fatalf("Sjis2Kuten %X: %+v", b, err)
+				}
+				if m != men || k != ku || n != ten {
+					t.Errorf("round trip %v-%v-%v -> %X -> %v-%v-%v", men, ku, ten, b, m, k, n)
+				}
+				if men == 1 && bytes.Equal(b, Kuten2Sjis(ku, ten)) != true {
+					t.Errorf("plane 1 should be same as Kuten2Sjis %v-%v: %X", ku, ten, b)
+				}
+			}
+		}
+	}
+	// plane 1: 94 ku, plane 2: 26 ku
+	if got, want := count, (94+26)*94; got != want {
+		t.Errorf("count got: %v want: %v", got, want)
+	}
+}
@@ -0,0 +1,200 @@
+package aozoraconv
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ConvFunc is a conversion function such as Conv, ConvRev, Encode and Decode
+type ConvFunc func(w io.Writer, r io.Reader, opts ...OptionFunc) error
+
+// BatchFailure is a file that failed to convert
+type BatchFailure struct {
+	Path string
+	Err  error
+}
+
+// BatchSummary is the result of batch conversion
+type BatchSummary struct {
+	Succeeded int
+	Failed    int
+	Failures  []BatchFailure
+}
+
+func (s *BatchSummary) add(path string, err error) {
+	if err == nil {
+		s.Succeeded += 1
+		return
+	}
+	s.Failed += 1
+	s.Failures = append(s.Failures, BatchFailure{Path: path, Err: err})
+}
+
+// ConvertTree converts every text file (*.txt) under srcDir with workers in parallel,
+// and writes it to the same relative path under dstDir, dstDir is skipped if it is under srcDir
+func ConvertTree(dstDir, srcDir string, workers int, conv ConvFunc, opts ...OptionFunc) (*BatchSummary, error) {
+	absDst, err := batchDstDir(dstDir, srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(chan string)
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		walkErr <- filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// do not convert output of this batch again
+				if abs, err := filepath.Abs(path); err == nil && abs == absDst {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(path), ".txt") != true {
+				return nil
+			}
+			rel, err := filepath.Rel(srcDir, path)
+			if err != nil {
+				return err
+			}
+			paths <- rel
+			return nil
+		})
+	}()
+
+	summary := convertBatch(dstDir, srcDir, paths, workers, conv, opts)
+	if err := <-walkErr; err != nil {
+		return summary, errors.WithStack(err)
+	}
+	return summary, nil
+}
+
+// ConvertFileList converts files listed in list (one path relative to srcDir per line)
+// with workers in parallel, and writes it to the same relative path under dstDir (dstDir must not be srcDir)
+func ConvertFileList(dstDir, srcDir string, list io.Reader, workers int, conv ConvFunc, opts ...OptionFunc) (*BatchSummary, error) {
+	if _, err := batchDstDir(dstDir, srcDir); err != nil {
+		return nil, err
+	}
+
+	paths := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		scan := bufio.NewScanner(list)
+		for scan.Scan() {
+			path := strings.TrimSpace(scan.Text())
+			if path == "" {
+				continue
+			}
+			paths <- filepath.Clean(path)
+		}
+		scanErr <- scan.Err()
+	}()
+
+	summary := convertBatch(dstDir, srcDir, paths, workers, conv, opts)
+	if err := <-scanErr; err != nil {
+		return summary, errors.WithStack(err)
+	}
+	return summary, nil
+}
+
+// batchDstDir returns absolute path of dstDir, output must not overwrite the source files
+func batchDstDir(dstDir, srcDir string) (string, error) {
+	absSrc, err := filepath.Abs(srcDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	absDst, err := filepath.Abs(dstDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if absSrc == absDst {
+		return "", errors.Errorf("dstDir must not be srcDir: %s", srcDir)
+	}
+	return absDst, nil
+}
+
+func convertBatch(dstDir, srcDir string, paths <-chan string, workers int, conv ConvFunc, opts []OptionFunc) *BatchSummary {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	type result struct {
+		path string
+		err  error
+	}
+	results := make(chan result)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				results <- result{path, convertFile(dstDir, srcDir, path, conv, opts)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	summary := new(BatchSummary)
+	for r := range results {
+		summary.add(r.path, r.err)
+	}
+	return summary
+}
+
+func convertFile(dstDir, srcDir, rel string, conv ConvFunc, opts []OptionFunc) error {
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("path should be relative to source directory: %s", rel)
+	}
+
+	input, err := os.Open(filepath.Join(srcDir, rel))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer input.Close()
+
+	outPath := filepath.Join(dstDir, rel)
+	if out, err := os.Stat(outPath); err == nil {
+		in, err := input.Stat()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if os.SameFile(in, out) {
+			return errors.Errorf("output is the same file as input: %s", outPath)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	output, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := conv(output, input, opts...); err != nil {
+		output.Close()
+		os.Remove(outPath)
+		return errors.WithStack(err)
+	}
+	if err := output.Close(); err != nil {
+		os.Remove(outPath)
+		return errors.WithStack(err)
+	}
+	return nil
+}
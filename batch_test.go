@@ -0,0 +1,149 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+}
+
+func TestConvertTree(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTestFiles(t, src, map[string][]byte{
+		"a.txt":          toSjis("田住生《たずみせい》\r\n"),
+		"sub/b.txt":      toSjis("フラ／＼\r\n"),
+		"sub/deep/c.TXT": toSjis("あ\r\n"),
+		"sub/fig.png":    []byte{0x89, 0x50, 0x4e, 0x47},
+	})
+
+	summary, err := ConvertTree(dst, src, 2, Decode, WithoutRuby(), WithoutRepeatTwo())
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if summary.Succeeded != 3 || summary.Failed != 0 {
+		t.Errorf("summary: %+v", summary)
+	}
+
+	expect := map[string]string{
+		"a.txt":          "田住生\r\n",
+		"sub/b.txt":      "フラフラ\r\n",
+		"sub/deep/c.TXT": "あ\r\n",
+	}
+	for name, want := range expect {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Errorf("%s: %+v", name, err)
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s: actual=%s", name, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "sub/fig.png")); os.IsNotExist(err) != true {
+		t.Errorf("non text file should not be converted")
+	}
+}
+
+func TestConvertTreeDstInSrc(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(src, "out")
+	writeTestFiles(t, src, map[string][]byte{
+		"a.txt":     toSjis("あ\r\n"),
+		"out/b.txt": toSjis("い\r\n"),
+	})
+
+	summary, err := ConvertTree(dst, src, 2, Decode)
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if summary.Succeeded != 1 || summary.Failed != 0 {
+		t.Errorf("dstDir should be skipped: %+v", summary)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "out/b.txt")); os.IsNotExist(err) != true {
+		t.Errorf("output should not be converted again")
+	}
+
+	if _, err := ConvertTree(src, src, 2, Decode); err == nil {
+		t.Errorf("same srcDir and dstDir must error")
+	}
+}
+
+func TestConvertFileList(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTestFiles(t, src, map[string][]byte{
+		"a.txt":     toSjis("あ\r\n"),
+		"sub/b.txt": toSjis("い\r\n"),
+	})
+
+	list := strings.NewReader("a.txt\n\nsub/b.txt\nmissing.txt\n../escape.txt\n")
+	summary, err := ConvertFileList(dst, src, list, 0, Decode)
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if summary.Succeeded != 2 || summary.Failed != 2 {
+		t.Errorf("summary: %+v", summary)
+	}
+	failed := map[string]bool{}
+	for _, f := range summary.Failures {
+		failed[f.Path] = true
+	}
+	if failed["missing.txt"] != true || failed[filepath.Clean("../escape.txt")] != true {
+		t.Errorf("failures: %+v", summary.Failures)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "missing.txt")); os.IsNotExist(err) != true {
+		t.Errorf("failed file should not be created")
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "sub/b.txt"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if string(data) != "い\r\n" {
+		t.Errorf("actual=%s", data)
+	}
+}
+
+func TestConvertFileListSameDir(t *testing.T) {
+	src := t.TempDir()
+	data := toSjis("あ\r\n")
+	writeTestFiles(t, src, map[string][]byte{"a.txt": data})
+
+	// batch -list with -o srcdir
+	if _, err := ConvertFileList(src, src, strings.NewReader("a.txt\n"), 1, Decode); err == nil {
+		t.Errorf("dstDir same as srcDir should be error")
+	}
+
+	// dstDir is a link to srcDir
+	dst := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(src, dst); err != nil {
+		t.Skipf("symlink: %+v", err)
+	}
+	summary, err := ConvertFileList(dst, src, strings.NewReader("a.txt\n"), 1, Decode)
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if summary.Succeeded != 0 || summary.Failed != 1 {
+		t.Errorf("summary: %+v", summary)
+	}
+
+	actual, err := os.ReadFile(filepath.Join(src, "a.txt"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if bytes.Equal(data, actual) != true {
+		t.Errorf("source should not be changed: actual=%x", actual)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+
+	"github.com/octu0/aozoraconv"
+)
+
+func runBatch(args []string) {
+	var (
+		outdir, listpath string
+		workers          int
+		conv             convFlags
+	)
+
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: aozoraconv batch -o outdir [-j workers] [-list file] srcdir\n")
+		fs.PrintDefaults()
+	}
+	conv.register(fs)
+	fs.StringVar(&outdir, "o", "", "output directory")
+	fs.StringVar(&listpath, "list", "", "file list (one path relative to srcdir per line, - for standard input)")
+	fs.IntVar(&workers, "j", runtime.NumCPU(), "number of parallel workers")
+	fs.Parse(args)
+
+	if outdir == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	srcdir := fs.Arg(0)
+
+	convert, err := conv.converter()
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	convFunc := func(w io.Writer, r io.Reader, _ ...aozoraconv.OptionFunc) error {
+		return convert(w, r)
+	}
+
+	var summary *aozoraconv.BatchSummary
+	switch listpath {
+	case "":
+		summary, err = aozoraconv.ConvertTree(outdir, srcdir, workers, convFunc)
+	case "-":
+		summary, err = aozoraconv.ConvertFileList(outdir, srcdir, os.Stdin, workers, convFunc)
+	default:
+		list, openErr := os.Open(listpath)
+		if openErr != nil {
+			log.Fatalf("error: %v", openErr)
+		}
+		defer list.Close()
+		summary, err = aozoraconv.ConvertFileList(outdir, srcdir, list, workers, convFunc)
+	}
+	if err != nil {
+		log.Fatalf("error: %+v", err)
+	}
+
+	for _, f := range summary.Failures {
+		fmt.Fprintf(os.Stderr, "failed: %s: %v\n", f.Path, f.Err)
+	}
+	fmt.Fprintf(os.Stderr, "succeeded: %d, failed: %d\n", summary.Succeeded, summary.Failed)
+	if 0 < summary.Failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/octu0/aozoraconv"
+)
+
+// lookupGaiji returns JisEntry from a character, "U+XXXX", "1-85-9" or "第3水準1-85-9"
+func lookupGaiji(s string) (aozoraconv.JisEntry, error) {
+	if strings.HasPrefix(s, "U+") || strings.HasPrefix(s, "u+") {
+		runes := []rune{}
+		for _, hex := range strings.Split(s[2:], "+") {
+			hex = strings.TrimPrefix(strings.TrimPrefix(hex, "U"), "u")
+			r, err := strconv.ParseUint(hex, 16, 32)
+			if err != nil {
+				return aozoraconv.JisEntry{}, fmt.Errorf("invalid codepoint: %s", s)
+			}
+			runes = append(runes, rune(r))
+		}
+		return aozoraconv.Uni2Jis(string(runes))
+	}
+	if entry, err := aozoraconv.ParseMenKuTen(s); err == nil {
+		return entry, nil
+	}
+	return aozoraconv.Uni2Jis(s)
+}
+
+// printGaiji prints character information, the gaiji annotation is printed with description
+// only for level 3 and 4 characters (level 1 and 2 characters are in JIS X 0208)
+func printGaiji(w io.Writer, entry aozoraconv.JisEntry, description string) error {
+	str, err := entry.Unicode()
+	if err != nil {
+		return err
+	}
+	codepoints := make([]string, 0, 2)
+	for _, r := range str {
+		codepoints = append(codepoints, fmt.Sprintf("U+%04X", r))
+	}
+	sjis, err := entry.ShiftJIS2004Bytes()
+	if err != nil {
+		return err
+	}
+	is0208 := aozoraconv.Is0208(int(entry.Men), int(entry.Ku), int(entry.Ten))
+
+	fmt.Fprintf(w, "character:      %s\n", str)
+	fmt.Fprintf(w, "unicode:        %s\n", strings.Join(codepoints, " "))
+	fmt.Fprintf(w, "men-ku-ten:     %s\n", entry.String())
+	fmt.Fprintf(w, "shift_jis-2004: %X\n", sjis)
+	if is0208 {
+		fmt.Fprintf(w, "shift_jis:      %X\n", aozoraconv.Kuten2Sjis(int(entry.Ku), int(entry.Ten)))
+	}
+	fmt.Fprintf(w, "jis level:      第%d水準\n", entry.Level())
+	fmt.Fprintf(w, "jis x 0208:     %v\n", is0208)
+	if entry.Level() <= 2 {
+		fmt.Fprintf(w, "annotation:     not gaiji\n")
+	} else {
+		fmt.Fprintf(w, "annotation:     %s\n", entry.GaijiAnnotation(description))
+	}
+	return nil
+}
+
+func runGaiji(args []string) {
+	fs := flag.NewFlagSet("gaiji", flag.ExitOnError)
+	description := fs.String("d", "〓", "description of gaiji annotation (「木＋世」)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: aozoraconv gaiji [-d description] <character | U+XXXX | 1-85-9 | 第3水準1-85-9> ...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	for i, arg := range fs.Args() {
+		entry, err := lookupGaiji(arg)
+		if err != nil {
+			log.Fatalf("error: %s: %v", arg, err)
+		}
+		if 0 < i {
+			fmt.Fprintln(os.Stdout)
+		}
+		if err := printGaiji(os.Stdout, entry, *description); err != nil {
+			log.Fatalf("error: %s: %v", arg, err)
+		}
+	}
+}
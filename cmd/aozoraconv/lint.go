@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/octu0/aozoraconv"
+)
+
+type lintResult struct {
+	Path string `json:"path"`
+	aozoraconv.Diagnostic
+}
+
+func lintFile(path string, utf8Input bool) ([]lintResult, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if utf8Input {
+		if _, err := io.Copy(buf, input); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := aozoraconv.Decode(buf, input); err != nil {
+			return nil, err
+		}
+	}
+
+	diags := aozoraconv.Lint(buf)
+	results := make([]lintResult, 0, len(diags))
+	for _, d := range diags {
+		results = append(results, lintResult{Path: path, Diagnostic: d})
+	}
+	return results, nil
+}
+
+func writeLintText(w io.Writer, results []lintResult) error {
+	for _, r := range results {
+		if _, err := fmt.Fprintf(w, "%s:%s\n", r.Path, r.Diagnostic.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLintJSON(w io.Writer, results []lintResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// sarif* are subset of SARIF 2.1.0
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+func writeLintSARIF(w io.Writer, results []lintResult) error {
+	rules := make([]sarifRule, 0, 16)
+	for _, r := range aozoraconv.LintRules() {
+		rules = append(rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfig{Level: string(r.Severity)},
+		})
+	}
+	sarifResults := make([]sarifResult, 0, len(results))
+	for _, r := range results {
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:  r.Rule,
+			Level:   string(r.Severity),
+			Message: sarifMessage{Text: r.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(r.Path)},
+					Region:           sarifRegion{StartLine: r.Line, StartColumn: r.Col},
+				},
+			}},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "aozoraconv",
+				InformationURI: "https://github.com/octu0/aozoraconv",
+				Rules:          rules,
+			}},
+			// Diagnostic.Col counts runes, not UTF-16 code units (default of SARIF)
+			ColumnKind: "unicodeCodePoints",
+			Results:    sarifResults,
+		}},
+	})
+}
+
+func runLint(args []string) {
+	var (
+		format    string
+		utf8Input bool
+		strict    bool
+	)
+
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: aozoraconv lint [-f text|json|sarif] [-utf8] [-strict] file ...\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&format, "f", "text", "output format (text, json or sarif)")
+	fs.BoolVar(&utf8Input, "utf8", false, "input is UTF-8 (default: Shift_JIS)")
+	fs.BoolVar(&strict, "strict", false, "exit with status 1 on warnings too")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) < 1 {
+		paths = []string{"-"}
+	}
+
+	results := make([]lintResult, 0, 64)
+	for _, path := range paths {
+		r, err := lintFile(path, utf8Input)
+		if err != nil {
+			log.Fatalf("error: %s: %v", path, err)
+		}
+		results = append(results, r...)
+	}
+
+	var err error
+	switch strings.ToLower(format) {
+	case "text":
+		err = writeLintText(os.Stdout, results)
+	case "json":
+		err = writeLintJSON(os.Stdout, results)
+	case "sarif":
+		err = writeLintSARIF(os.Stdout, results)
+	default:
+		log.Fatalf("error: unknown format: %s", format)
+	}
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	for _, r := range results {
+		if r.Severity == aozoraconv.SeverityError || strict {
+			os.Exit(1)
+		}
+	}
+}
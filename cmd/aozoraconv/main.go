@@ -1,20 +1,25 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/octu0/aozoraconv"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
 )
 
 func getOuput(path string) (output io.Writer, err error) {
 	if path == "" {
 		return os.Stdout, nil
 	}
-	output, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	output, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return nil, err
 	}
@@ -35,30 +40,358 @@ func getInput(path string, stdin bool) (input io.Reader, err error) {
 	return input, nil
 }
 
+func extractImages(dir string, archive *aozoraconv.ZipArchive) error {
+	for _, name := range archive.Images {
+		if err := extractImage(dir, name, archive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractImage(dir, name string, src aozoraconv.ImageSource) error {
+	img, err := src.OpenImage(name)
+	if err != nil {
+		return err
+	}
+	defer img.Close()
+
+	outPath := filepath.Join(dir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dir, outPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("image outside of %s: %s", dir, name)
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return err
+	}
+	output, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(output, img); err != nil {
+		output.Close()
+		return err
+	}
+	return output.Close()
+}
+
+// convFlags is set of flags that controls conversion
+type convFlags struct {
+	useSjis, useUtf8 bool
+	encoding         string
+	strip            bool
+	keepHeader       bool
+	keepRuby         bool
+	keepAnnotation   bool
+	expandRepeat     bool
+	resolveGaiji     bool
+	imagePlaceholder bool
+	kanbunReorder    bool
+	strictNotation   bool
+	reading          bool
+	readingKatakana  bool
+	modernize        bool
+	modernizeDryRun  bool
+	fullWidth        bool
+	halfWidthAlnum   bool
+	nfc              bool
+	compat           string
+	ivs              bool
+	ivsGaiji         bool
+	jis2004          bool
+	raw              bool
+}
+
+func (c *convFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.encoding, "e", "sjis", "set output encoding (sjis or utf8)")
+	fs.BoolVar(&c.useSjis, "s", false, "convert from UTF-8 into Shift_JIS")
+	fs.BoolVar(&c.useUtf8, "u", false, "convert from Shift_JIS into UTF-8")
+	fs.BoolVar(&c.strip, "strip", false, "strip header, footer, ruby and annotations, expand repeat marks (plain text)")
+	fs.BoolVar(&c.keepHeader, "keep-header", false, "keep header and footer with -strip")
+	fs.BoolVar(&c.keepRuby, "keep-ruby", false, "keep ruby with -strip")
+	fs.BoolVar(&c.keepAnnotation, "keep-annotation", false, "keep annotations with -strip")
+	fs.BoolVar(&c.expandRepeat, "expand-repeat", false, "expand repeat marks (／＼)")
+	fs.BoolVar(&c.resolveGaiji, "resolve-gaiji", false, "replace gaiji annotations with Unicode characters (UTF-8 output only)")
+	fs.BoolVar(&c.imagePlaceholder, "image-placeholder", false, "replace illustration annotations with placeholder (e.g. ［挿絵］) instead of dropping with -strip")
+	fs.BoolVar(&c.kanbunReorder, "kanbun-reorder", false, "reorder classical chinese with kaeriten into reading order (書き下し)")
+	fs.BoolVar(&c.strictNotation, "strict-notation", false, "stop at malformed notation and report the line and column")
+	fs.BoolVar(&c.reading, "reading", false, "replace ruby bases with readings, remove annotations and expand repeat marks (for text-to-speech)")
+	fs.BoolVar(&c.readingKatakana, "reading-katakana", false, "-reading with readings in katakana")
+	fs.BoolVar(&c.modernize, "modernize", false, "convert 旧字旧仮名 into 新字新仮名")
+	fs.BoolVar(&c.modernizeDryRun, "modernize-dry-run", false, "report changes of -modernize to standard error without changing text")
+	fs.BoolVar(&c.fullWidth, "full-width", false, "convert ASCII and half-width katakana into full-width (except 縦中横 and 横組み)")
+	fs.BoolVar(&c.halfWidthAlnum, "half-width-alnum", false, "convert full-width alphanumerics into ASCII and half-width katakana into full-width")
+	fs.BoolVar(&c.nfc, "nfc", false, "normalize text into NFC keeping CJK compatibility ideographs")
+	fs.StringVar(&c.compat, "compat", "preserve", "handling of CJK compatibility ideographs (preserve, unified or jis)")
+	fs.BoolVar(&c.ivs, "ivs", false, "map variation sequences into JIS X 0213 characters or remove variation selectors")
+	fs.BoolVar(&c.ivsGaiji, "ivs-gaiji", false, "-ivs with gaiji notation for variants which are not in JIS X 0213")
+	fs.BoolVar(&c.jis2004, "jis2004", false, "use Shift_JIS-2004 (JIS X 0213) instead of Shift_JIS")
+	fs.BoolVar(&c.raw, "raw", false, "encoding conversion only, without any character replacement")
+}
+
+// outputEncoding returns normalized output encoding ("utf8" or "sjis")
+func (c *convFlags) outputEncoding() (string, error) {
+	if c.useSjis && c.useUtf8 {
+		return "", fmt.Errorf("only -s or -u can be enabled")
+	}
+	if c.useUtf8 {
+		return "utf8", nil
+	}
+	if c.useSjis {
+		return "sjis", nil
+	}
+	switch strings.ToLower(c.encoding) {
+	case "utf8", "utf-8":
+		return "utf8", nil
+	case "sjis", "shift_jis":
+		return "sjis", nil
+	}
+	return "", fmt.Errorf("require encoding args: -s (Shift_JIS) or -u (UTF-8) or -e sting")
+}
+
+func (c *convFlags) options() ([]aozoraconv.OptionFunc, error) {
+	if c.raw && (c.strip || c.expandRepeat || c.resolveGaiji || c.imagePlaceholder || c.kanbunReorder || c.strictNotation || c.reading || c.readingKatakana || c.modernize || c.modernizeDryRun || c.fullWidth || c.halfWidthAlnum || c.nfc || c.compat != "preserve" || c.ivs || c.ivsGaiji) {
+		return nil, fmt.Errorf("-raw can not be used with other transforms")
+	}
+	if c.strip != true && (c.keepHeader || c.keepRuby || c.keepAnnotation) {
+		return nil, fmt.Errorf("-keep-header, -keep-ruby and -keep-annotation require -strip")
+	}
+
+	options := []aozoraconv.OptionFunc{}
+	if c.strip {
+		if c.keepHeader != true {
+			options = append(options, aozoraconv.WithoutHeader())
+		}
+		if c.keepRuby != true {
+			options = append(options, aozoraconv.WithoutRuby())
+		}
+		if c.keepAnnotation != true {
+			options = append(options, aozoraconv.WithoutAnnotation())
+		}
+		options = append(options, aozoraconv.WithoutRepeatTwo())
+	}
+	if c.expandRepeat {
+		options = append(options, aozoraconv.WithoutRepeatTwo())
+	}
+	if c.resolveGaiji {
+		options = append(options, aozoraconv.WithResolveGaiji())
+	}
+	if c.imagePlaceholder {
+		options = append(options, aozoraconv.WithImagePlaceholder())
+	}
+	if c.kanbunReorder {
+		options = append(options, aozoraconv.WithKanbunReorder())
+	}
+	if c.strictNotation {
+		options = append(options, aozoraconv.WithStrictNotation())
+	}
+	policy, err := compatPolicy(c.compat)
+	if err != nil {
+		return nil, err
+	}
+	if c.nfc {
+		options = append(options, aozoraconv.WithNFC())
+	}
+	if policy != aozoraconv.CompatPreserve {
+		options = append(options, aozoraconv.WithCompatIdeograph(policy))
+	}
+	switch {
+	case c.ivsGaiji:
+		options = append(options, aozoraconv.WithIVSGaiji())
+	case c.ivs:
+		options = append(options, aozoraconv.WithIVS())
+	}
+	if c.fullWidth && c.halfWidthAlnum {
+		return nil, fmt.Errorf("only -full-width or -half-width-alnum can be enabled")
+	}
+	if c.fullWidth {
+		options = append(options, aozoraconv.WithFullWidth())
+	}
+	if c.halfWidthAlnum {
+		options = append(options, aozoraconv.WithHalfWidthAlnum())
+	}
+	if c.modernize && c.modernizeDryRun != true {
+		options = append(options, aozoraconv.WithModernize())
+	}
+	switch {
+	case c.readingKatakana:
+		options = append(options, aozoraconv.WithRubyReadingKatakana())
+	case c.reading:
+		options = append(options, aozoraconv.WithRubyReading())
+	}
+	return options, nil
+}
+
+func compatPolicy(s string) (aozoraconv.CompatPolicy, error) {
+	switch strings.ToLower(s) {
+	case "preserve", "":
+		return aozoraconv.CompatPreserve, nil
+	case "unified":
+		return aozoraconv.CompatUnified, nil
+	case "jis":
+		return aozoraconv.CompatJIS, nil
+	}
+	return aozoraconv.CompatPreserve, fmt.Errorf("unknown -compat: %s (preserve, unified or jis)", s)
+}
+
+// converter returns conversion function from flags
+func (c *convFlags) converter() (func(io.Writer, io.Reader) error, error) {
+	encoding, err := c.outputEncoding()
+	if err != nil {
+		return nil, err
+	}
+	options, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	if c.resolveGaiji && encoding == "sjis" {
+		return nil, fmt.Errorf("-resolve-gaiji requires UTF-8 output")
+	}
+
+	if c.raw {
+		return func(output io.Writer, input io.Reader) error {
+			decoder, encoder := japanese.ShiftJIS.NewDecoder(), japanese.ShiftJIS.NewEncoder()
+			if c.jis2004 {
+				decoder, encoder = aozoraconv.ShiftJIS2004.NewDecoder(), aozoraconv.ShiftJIS2004.NewEncoder()
+			}
+			var reader io.Reader
+			switch encoding {
+			case "utf8":
+				reader = transform.NewReader(input, decoder)
+			default:
+				reader = transform.NewReader(input, encoder)
+			}
+			_, err := io.Copy(output, reader)
+			return err
+		}, nil
+	}
+
+	return c.convertFunc(encoding, options), nil
+}
+
+// decoder returns conversion function from Shift_JIS into UTF-8 for html, epub and ssml
+func (c *convFlags) decoder() (func(io.Writer, io.Reader) error, error) {
+	if c.useSjis {
+		return nil, fmt.Errorf("-s can not be used with -f html, epub or ssml (Shift_JIS input)")
+	}
+	if c.raw {
+		return nil, fmt.Errorf("-raw can not be used with -f html, epub or ssml")
+	}
+	options, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	return c.convertFunc("utf8", options), nil
+}
+
+func (c *convFlags) convertFunc(encoding string, options []aozoraconv.OptionFunc) func(io.Writer, io.Reader) error {
+	return func(output io.Writer, input io.Reader) error {
+		opts := options
+		var modernizer *aozoraconv.ModernizeEscaper
+		if c.modernizeDryRun {
+			modernizer = aozoraconv.NewModernizeEscaper(true)
+			opts = append(opts[:len(opts):len(opts)], aozoraconv.WithEscaperAfter(aozoraconv.StageModernize, modernizer))
+		}
+
+		var err error
+		switch {
+		case encoding == "utf8" && c.jis2004:
+			err = aozoraconv.Decode2004(output, input, opts...)
+		case encoding == "utf8":
+			err = aozoraconv.Decode(output, input, opts...)
+		case c.jis2004:
+			err = aozoraconv.Encode2004(output, input, opts...)
+		default:
+			err = aozoraconv.Encode(output, input, opts...)
+		}
+		if modernizer != nil {
+			for _, change := range modernizer.Changes() {
+				fmt.Fprintln(os.Stderr, change.String())
+			}
+		}
+		return err
+	}
+}
+
 func main() {
+	if 1 < len(os.Args) {
+		switch os.Args[1] {
+		case "gaiji":
+			runGaiji(os.Args[2:])
+			return
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		}
+	}
+
 	var (
-		useSjis, useUtf8 bool
-		useStdin         bool
-		path, outpath    string
-		encoding         string
+		useStdin      bool
+		path, outpath string
+		imagedir      string
+		format        string
+		conv          convFlags
 	)
 
-	flag.StringVar(&encoding, "e", "sjis", "set output encoding (sjis or utf8)")
-	flag.BoolVar(&useSjis, "s", false, "convert from UTF-8 into Shift_JIS")
-	flag.BoolVar(&useUtf8, "u", false, "convert from Shift_JIS into UTF-8")
+	conv.register(flag.CommandLine)
 	flag.StringVar(&outpath, "o", "", "output filename")
 	flag.BoolVar(&useStdin, "stdin", false, "use standard input")
+	flag.StringVar(&imagedir, "images", "", "extract bundled images into directory (.zip input only)")
+	flag.StringVar(&format, "f", "text", "output format (text, html, epub or ssml), html, epub and ssml read Shift_JIS input and apply transforms before rendering")
 	flag.Parse()
 
-	if useSjis && useUtf8 {
-		log.Fatalf("only -s or -u can be enabled")
+	format = strings.ToLower(format)
+	var convert func(io.Writer, io.Reader) error
+	var err error
+	switch format {
+	case "html", "epub", "ssml":
+		convert, err = conv.decoder()
+	default:
+		convert, err = conv.converter()
+	}
+	if err != nil {
+		log.Fatalf("error: %v", err)
 	}
 
 	path = flag.Arg(0)
 
-	input, err := getInput(path, useStdin)
-	if err != nil {
-		log.Fatalf("error: %v", err)
+	var input io.Reader
+	var images aozoraconv.ImageSource
+	if path != "" {
+		images = aozoraconv.NewDirImageSource(filepath.Dir(path))
+	}
+	if useStdin != true && strings.EqualFold(filepath.Ext(path), ".zip") {
+		archive, err := aozoraconv.OpenZip(path)
+		if err != nil {
+			log.Fatalf("error: %+v", err)
+		}
+		defer archive.Close()
+
+		if imagedir != "" {
+			if err := extractImages(imagedir, archive); err != nil {
+				log.Fatalf("error: %+v", err)
+			}
+		}
+		images = archive
+		text, err := archive.OpenText()
+		if err != nil {
+			log.Fatalf("error: %+v", err)
+		}
+		defer text.Close()
+		input = text
+	} else {
+		if imagedir != "" {
+			log.Fatalf("error: -images requires .zip input")
+		}
+		input, err = getInput(path, useStdin)
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
 	}
 
 	output, err := getOuput(outpath)
@@ -66,29 +399,20 @@ func main() {
 		log.Fatalf("error: %v", err)
 	}
 
-	if useUtf8 {
-		encoding = "utf8"
-	}
-	if useSjis {
-		encoding = "sjis"
-	}
-
-	options := []aozoraconv.OptionFunc{}
-	options = append(options, aozoraconv.WithoutHeader())
-	options = append(options, aozoraconv.WithoutRuby())
-	options = append(options, aozoraconv.WithoutAnnotation())
-	options = append(options, aozoraconv.WithoutRepeatTwo())
-
-	switch strings.ToLower(encoding) {
-	case "utf8", "utf-8":
-		if err := aozoraconv.Encode(output, input, options...); err != nil {
+	switch format {
+	case "text":
+		if err := convert(output, input); err != nil {
+			var notationErr *aozoraconv.NotationError
+			if errors.As(err, &notationErr) {
+				log.Fatalf("error: %s:%v", path, notationErr)
+			}
 			log.Fatalf("error: %+v", err)
 		}
-	case "sjis", "shift_jis":
-		if err := aozoraconv.Decode(output, input, options...); err != nil {
+	case "html", "epub", "ssml":
+		if err := render(format, output, input, images, convert); err != nil {
 			log.Fatalf("error: %+v", err)
 		}
 	default:
-		log.Fatalf("require encoding args: -s (Shift_JIS) or -u (UTF-8) or -e sting")
+		log.Fatalf("error: unknown format: %s", format)
 	}
 }
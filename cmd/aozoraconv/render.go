@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/octu0/aozoraconv"
+)
+
+// render converts Aozora Bunko format text (Shift_JIS) into html, epub or ssml,
+// decode converts input into UTF-8 with transforms before parsing
+func render(format string, output io.Writer, input io.Reader, images aozoraconv.ImageSource, decode func(io.Writer, io.Reader) error) error {
+	buf := bytes.NewBuffer(nil)
+	if err := decode(buf, input); err != nil {
+		return err
+	}
+	doc, err := aozoraconv.Parse(buf)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "html":
+		return aozoraconv.RenderHTML(output, doc)
+	case "epub":
+		return aozoraconv.WriteEPUB(output, doc, images)
+	case "ssml":
+		return aozoraconv.RenderSSML(output, doc)
+	}
+	return fmt.Errorf("unknown format: %s", format)
+}
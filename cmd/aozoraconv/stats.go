@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/octu0/aozoraconv"
+)
+
+type statsResult struct {
+	Path string `json:"path"`
+	*aozoraconv.TextStats
+}
+
+// statsPaths expands directories into text files (*.txt) under them
+func statsPaths(args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if arg == "-" || err != nil || info.IsDir() != true {
+			paths = append(paths, arg)
+			continue
+		}
+		walkErr := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || strings.EqualFold(filepath.Ext(path), ".txt") != true {
+				return nil
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	return paths, nil
+}
+
+func statsFile(path string, utf8Input bool, opts []aozoraconv.StatsOptionFunc) (statsResult, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return statsResult{}, err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if utf8Input {
+		if _, err := io.Copy(buf, input); err != nil {
+			return statsResult{}, err
+		}
+	} else {
+		if err := aozoraconv.Decode(buf, input); err != nil {
+			return statsResult{}, err
+		}
+	}
+
+	doc, err := aozoraconv.Parse(buf)
+	if err != nil {
+		return statsResult{}, err
+	}
+	return statsResult{Path: path, TextStats: aozoraconv.Stats(doc, opts...)}, nil
+}
+
+func writeStatsJSON(w io.Writer, results []statsResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func writeStatsCSV(w io.Writer, results []statsResult) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{
+		"path", "title", "author", "lines", "chars", "kanji", "hiragana", "katakana",
+		"jis_level1", "jis_level2", "jis_level3", "jis_level4", "non_jis", "gaiji",
+		"ruby", "ruby_chars", "ruby_density", "kanji_ratio", "reading_minutes", "top_chars",
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		top := make([]string, 0, len(r.TopChars))
+		for _, c := range r.TopChars {
+			top = append(top, fmt.Sprintf("%s:%d", c.Char, c.Count))
+		}
+		err := cw.Write([]string{
+			r.Path, r.Title, r.Author,
+			strconv.Itoa(r.Lines), strconv.Itoa(r.Chars), strconv.Itoa(r.Kanji), strconv.Itoa(r.Hiragana), strconv.Itoa(r.Katakana),
+			strconv.Itoa(r.JISLevel1), strconv.Itoa(r.JISLevel2), strconv.Itoa(r.JISLevel3), strconv.Itoa(r.JISLevel4),
+			strconv.Itoa(r.NonJIS), strconv.Itoa(r.Gaiji), strconv.Itoa(r.Ruby), strconv.Itoa(r.RubyChars),
+			strconv.FormatFloat(r.RubyDensity, 'f', 4, 64),
+			strconv.FormatFloat(r.KanjiRatio, 'f', 4, 64),
+			strconv.FormatFloat(r.ReadingMinutes, 'f', 1, 64),
+			strings.Join(top, " "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func runStats(args []string) {
+	var (
+		format    string
+		utf8Input bool
+		topN      int
+		cpm       int
+	)
+
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: aozoraconv stats [-f json|csv] [-utf8] [-top N] [-cpm N] file_or_dir ...\n")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&format, "f", "json", "output format (json or csv)")
+	flags.BoolVar(&utf8Input, "utf8", false, "input is UTF-8 (default: Shift_JIS)")
+	flags.IntVar(&topN, "top", 10, "number of the most frequent characters")
+	flags.IntVar(&cpm, "cpm", 500, "characters per minute to estimate reading time")
+	flags.Parse(args)
+
+	args = flags.Args()
+	if len(args) < 1 {
+		args = []string{"-"}
+	}
+	paths, err := statsPaths(args)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	opts := []aozoraconv.StatsOptionFunc{
+		aozoraconv.WithStatsTopN(topN),
+		aozoraconv.WithStatsReadingSpeed(cpm),
+	}
+	results := make([]statsResult, 0, len(paths))
+	for _, path := range paths {
+		r, err := statsFile(path, utf8Input, opts)
+		if err != nil {
+			log.Fatalf("error: %s: %v", path, err)
+		}
+		results = append(results, r)
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		err = writeStatsJSON(os.Stdout, results)
+	case "csv":
+		err = writeStatsCSV(os.Stdout, results)
+	default:
+		log.Fatalf("error: unknown format: %s", format)
+	}
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+}
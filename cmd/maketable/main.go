@@ -117,7 +117,7 @@ func main() {
 	getTable(url)
 
 	fmt.Printf("//JisEntry is jis character with men, ku, ten\n")
-	fmt.Printf("type JisEntry struct {\n	men, ku, ten int8\n}\n")
+	fmt.Printf("type JisEntry struct {\n	Men, Ku, Ten int8\n}\n")
 
 	fmt.Printf("// jis0213Decode is the decoding table from JIS 0213 code to Unicode.\n// It is defined at %s\n",
 		url)
@@ -128,14 +128,13 @@ func main() {
 		for _, m2 := range m1 {
 			fmt.Printf("\t\t{")
 			counter = 0
+			// empty cells are kept to index by ten
 			for _, m3 := range m2 {
-				if m3 != "" {
-					fmt.Printf("\t%q,", m3)
-					counter++
-					if counter >= 8 {
-						counter = 0
-						fmt.Printf("\n\t\t")
-					}
+				fmt.Printf("\t%q,", m3)
+				counter++
+				if counter >= 8 {
+					counter = 0
+					fmt.Printf("\n\t\t")
 				}
 			}
 			fmt.Printf("\t},\n")
@@ -218,7 +217,7 @@ func main() {
 		for _, k2 := range keys2 {
 			u2 := int32(k2.Int())
 			v := multichars[u1][u2]
-			fmt.Printf("\t\t0x%X: JisEntry{men: %d, ku: %d, ten: %d},\n", u2, v.men, v.ku, v.ten)
+			fmt.Printf("\t\t0x%X: JisEntry{Men: %d, Ku: %d, Ten: %d},\n", u2, v.men, v.ku, v.ten)
 		}
 		fmt.Printf("\t},\n")
 	}
@@ -0,0 +1,143 @@
+package aozoraconv
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CompatPolicy is a handling of CJK compatibility ideographs (U+F900-U+FAFF,
+// U+2F800-U+2FA1F) which are changed into the unified ideographs by Unicode normalization
+type CompatPolicy int
+
+const (
+	// CompatPreserve keeps compatibility ideographs
+	CompatPreserve CompatPolicy = iota
+	// CompatUnified maps compatibility ideographs into the unified ideographs (塚 U+FA10 -> 塚 U+585A)
+	CompatUnified
+	// CompatJIS keeps compatibility ideographs in JIS X 0213 and maps the others
+	// into the unified ideographs (塚 U+FA10 is 1-15-55 but 塚 U+585A is 1-36-45)
+	CompatJIS
+)
+
+func (p CompatPolicy) String() string {
+	switch p {
+	case CompatPreserve:
+		return "preserve"
+	case CompatUnified:
+		return "unified"
+	case CompatJIS:
+		return "jis"
+	}
+	return "unknown"
+}
+
+var (
+	unifiedCompatOnce sync.Once
+	unifiedCompat     map[rune][]rune
+)
+
+// compatDecomposition returns the unified ideograph of CJK compatibility ideograph,
+// ok is false if r is not a compatibility ideograph (U+FA0E 﨎, U+FA11 﨑 etc are unified ideographs in the block)
+func compatDecomposition(r rune) (rune, bool) {
+	if (0xf900 <= r && r <= 0xfaff) != true && (0x2f800 <= r && r <= 0x2fa1f) != true {
+		return r, false
+	}
+	d := norm.NFD.String(string(r))
+	u, size := utf8.DecodeRuneInString(d)
+	if size != len(d) || u == r {
+		return r, false
+	}
+	return u, true
+}
+
+// compatIdeographs returns compatibility ideographs of unified ideograph
+func compatIdeographs(u rune) []rune {
+	unifiedCompatOnce.Do(func() {
+		unifiedCompat = make(map[rune][]rune, 1024)
+		for _, block := range [][2]rune{{0xf900, 0xfaff}, {0x2f800, 0x2fa1f}} {
+			for r := block[0]; r <= block[1]; r += 1 {
+				if d, ok := compatDecomposition(r); ok {
+					unifiedCompat[d] = append(unifiedCompat[d], r)
+				}
+			}
+		}
+	})
+	return unifiedCompat[u]
+}
+
+// canonicalEquivalents returns ideographs which are canonical equivalent to r,
+// the unified ideograph is the first for compatibility ideographs
+func canonicalEquivalents(r rune) []rune {
+	u, ok := compatDecomposition(r)
+	if ok != true {
+		return compatIdeographs(r)
+	}
+	eq := []rune{u}
+	for _, c := range compatIdeographs(u) {
+		if c != r {
+			eq = append(eq, c)
+		}
+	}
+	return eq
+}
+
+// normalizeEscaper normalizes text into NFC keeping CJK compatibility
+// ideographs, then compatibility ideographs are handled by policy
+type normalizeEscaper struct {
+	nfc    bool
+	policy CompatPolicy
+}
+
+func (e *normalizeEscaper) Escape(src string) (string, bool) {
+	buf := new(strings.Builder)
+	buf.Grow(len(src))
+	last := 0
+	for i, r := range src {
+		if _, ok := compatDecomposition(r); ok != true {
+			continue
+		}
+		buf.WriteString(e.normalize(src[last:i]))
+		buf.WriteRune(e.compat(r))
+		last = i + utf8.RuneLen(r)
+	}
+	buf.WriteString(e.normalize(src[last:]))
+	return buf.String(), true
+}
+
+func (e *normalizeEscaper) normalize(s string) string {
+	if e.nfc {
+		return norm.NFC.String(s)
+	}
+	return s
+}
+
+func (e *normalizeEscaper) compat(r rune) rune {
+	switch e.policy {
+	case CompatUnified:
+		u, _ := compatDecomposition(r)
+		return u
+	case CompatJIS:
+		if _, ok := lookupJis(r); ok {
+			return r
+		}
+		u, _ := compatDecomposition(r)
+		return u
+	}
+	return r
+}
+
+func newNormalizeEscaper(nfc bool, policy CompatPolicy) *normalizeEscaper {
+	return &normalizeEscaper{
+		nfc:    nfc,
+		policy: policy,
+	}
+}
+
+// NewNormalizeEscaper returns Escaper that normalizes text into NFC if nfc is true
+// and handles CJK compatibility ideographs by policy
+func NewNormalizeEscaper(nfc bool, policy CompatPolicy) Escaper {
+	return newNormalizeEscaper(nfc, policy)
+}
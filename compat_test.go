@@ -0,0 +1,59 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEscaper(t *testing.T) {
+	tests := []struct {
+		in     string
+		nfc    bool
+		policy CompatPolicy
+		expect string
+	}{
+		{"が塚豈", false, CompatPreserve, "が塚豈"},
+		{"が塚豈", true, CompatPreserve, "が塚豈"},
+		{"が塚豈", true, CompatUnified, "が塚豈"},
+		{"が塚豈", false, CompatJIS, "が塚豈"},
+		{"﨎﨑", true, CompatUnified, "﨎﨑"},
+	}
+	for _, tc := range tests {
+		actual, ok := newNormalizeEscaper(tc.nfc, tc.policy).Escape(tc.in)
+		if ok != true {
+			t.Errorf("always true")
+		}
+		if actual != tc.expect {
+			t.Errorf("%s %s: expect=%+q actual=%+q", tc.in, tc.policy, tc.expect, actual)
+		}
+	}
+}
+
+func TestEncodeCompatIdeograph(t *testing.T) {
+	if err := Encode(bytes.NewBuffer(nil), strings.NewReader("\uF900\r\n")); err == nil {
+		t.Errorf("compatibility ideograph is not in Shift_JIS without WithCompatIdeograph")
+	}
+	expect := bytes.NewBuffer(nil)
+	if err := Encode(expect, strings.NewReader("\u8C48\r\n")); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	actual := bytes.NewBuffer(nil)
+	if err := Encode(actual, strings.NewReader("\uF900\r\n"), WithCompatIdeograph(CompatUnified)); err != nil {
+		t.Fatalf("compatibility ideograph should be encoded: %+v", err)
+	}
+	if bytes.Equal(expect.Bytes(), actual.Bytes()) != true {
+		t.Errorf("expect=%x actual=%x", expect.Bytes(), actual.Bytes())
+	}
+}
+
+func TestConvNFCCompat(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	in := "塚が\r\n"
+	if err := Conv(out, strings.NewReader(in), WithNFC(), WithCompatIdeograph(CompatUnified)); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if expect := "塚が\r\n"; out.String() != expect {
+		t.Errorf("expect=%+q actual=%+q", expect, out.String())
+	}
+}
@@ -0,0 +1,392 @@
+package aozoraconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type EPUBOptionFunc func(*epubOption)
+
+type epubOption struct {
+	Title      string
+	Author     string
+	Identifier string
+	Modified   time.Time
+	Horizontal bool
+}
+
+// WithEPUBTitle sets title (default: title in header)
+func WithEPUBTitle(title string) EPUBOptionFunc {
+	return func(opt *epubOption) {
+		opt.Title = title
+	}
+}
+
+// WithEPUBAuthor sets author (default: author in header)
+func WithEPUBAuthor(author string) EPUBOptionFunc {
+	return func(opt *epubOption) {
+		opt.Author = author
+	}
+}
+
+// WithEPUBIdentifier sets unique identifier (default: generated from title and author)
+func WithEPUBIdentifier(id string) EPUBOptionFunc {
+	return func(opt *epubOption) {
+		opt.Identifier = id
+	}
+}
+
+// WithEPUBModified sets last modified time (default: current time)
+func WithEPUBModified(t time.Time) EPUBOptionFunc {
+	return func(opt *epubOption) {
+		opt.Modified = t
+	}
+}
+
+// WithEPUBHorizontal sets horizontal writing mode (default: vertical)
+func WithEPUBHorizontal() EPUBOptionFunc {
+	return func(opt *epubOption) {
+		opt.Horizontal = true
+	}
+}
+
+func newEPUBOption(funcs ...EPUBOptionFunc) *epubOption {
+	opt := &epubOption{
+		Title:      "",
+		Author:     "",
+		Identifier: "",
+		Modified:   time.Now(),
+		Horizontal: false,
+	}
+	for _, fn := range funcs {
+		fn(opt)
+	}
+	return opt
+}
+
+type epubChapter struct {
+	Name  string
+	Title string
+	Break *PageBreak
+	Lines []*Line
+}
+
+type epubImage struct {
+	ID        string
+	Name      string
+	MediaType string
+}
+
+// WriteEPUB writes document as EPUB 3, images referenced by illustration
+// annotations are packaged from images (can be nil if document has no images)
+func WriteEPUB(w io.Writer, doc *Document, images ImageSource, opts ...EPUBOptionFunc) error {
+	opt := newEPUBOption(opts...)
+	title, author := doc.Title()
+	if opt.Title == "" {
+		opt.Title = title
+	}
+	if opt.Author == "" {
+		opt.Author = author
+	}
+	if opt.Identifier == "" {
+		opt.Identifier = epubIdentifier(opt.Title, opt.Author)
+	}
+
+	chapters := epubChapters(doc, opt)
+	imgs, err := epubImages(doc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if 0 < len(imgs) && images == nil {
+		return errors.Errorf("image source is required: document has %d images", len(imgs))
+	}
+
+	zw := zip.NewWriter(w)
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", epubPackage(opt, chapters, imgs)},
+		{"OEBPS/nav.xhtml", epubNav(opt, chapters)},
+		{"OEBPS/style.css", epubStyle(opt)},
+	}
+	for _, f := range files {
+		if err := writeZipFile(zw, f.name, bytes.NewReader(f.data)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	htmlOpt := newHTMLOption(WithImagePrefix("../images/"), WithStylesheet("../style.css"))
+	for _, c := range chapters {
+		htmlOpt.Title = c.Title
+		fw, err := zw.Create("OEBPS/text/" + c.Name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := writeHTMLHeader(fw, htmlOpt); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := writeHTMLLines(fw, c.Lines, htmlOpt); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := writeHTMLFooter(fw); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, img := range imgs {
+		if err := writeEPUBImage(zw, img, images); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// epubChapters splits document into chapters at page breaks
+func epubChapters(doc *Document, opt *epubOption) []epubChapter {
+	chapters := make([]epubChapter, 0, 8)
+	for _, sec := range doc.Sections() {
+		if len(sec.Lines) < 1 {
+			continue
+		}
+		title := opt.Title
+		if 0 < len(chapters) {
+			title = sectionTitle(sec, fmt.Sprintf("%s (%d)", opt.Title, len(chapters)+1))
+		}
+		chapters = append(chapters, epubChapter{
+			Name:  fmt.Sprintf("chapter%04d.xhtml", len(chapters)+1),
+			Title: title,
+			Break: sec.Break,
+			Lines: sec.Lines,
+		})
+	}
+	if len(chapters) < 1 {
+		chapters = append(chapters, epubChapter{Name: "chapter0001.xhtml", Title: opt.Title})
+	}
+	return chapters
+}
+
+// sectionTitle returns the first non-empty line of section
+func sectionTitle(sec *Section, defaultTitle string) string {
+	const maxTitleLength = 40
+	for _, l := range sec.Lines {
+		text := []rune(strings.TrimSpace(strings.TrimLeft(PlainText(l.Nodes), "　")))
+		if len(text) < 1 {
+			continue
+		}
+		if maxTitleLength < len(text) {
+			return string(text[:maxTitleLength]) + "…"
+		}
+		return string(text)
+	}
+	return defaultTitle
+}
+
+// epubPageSpread returns itemref properties for page break starts chapter
+func epubPageSpread(pb *PageBreak, horizontal bool) string {
+	if pb == nil {
+		return ""
+	}
+	switch pb.Kind {
+	case PageBreakRecto:
+		if horizontal {
+			return "page-spread-right"
+		}
+		return "page-spread-left"
+	case PageBreakSpread:
+		if horizontal {
+			return "page-spread-left"
+		}
+		return "page-spread-right"
+	}
+	return ""
+}
+
+func writeZipFile(zw *zip.Writer, name string, r io.Reader) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func writeEPUBImage(zw *zip.Writer, img epubImage, images ImageSource) error {
+	r, err := images.OpenImage(img.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	return writeZipFile(zw, "OEBPS/images/"+img.Name, r)
+}
+
+// epubImages returns unique images referenced in document (including images in layouts)
+func epubImages(doc *Document) ([]epubImage, error) {
+	imgs := make([]epubImage, 0)
+	seen := make(map[string]bool)
+	for _, l := range doc.Lines {
+		var err error
+		if imgs, err = appendEPUBImages(imgs, seen, l.Nodes); err != nil {
+			return nil, err
+		}
+	}
+	return imgs, nil
+}
+
+func appendEPUBImages(imgs []epubImage, seen map[string]bool, nodes []Node) ([]epubImage, error) {
+	for _, n := range nodes {
+		var err error
+		switch v := n.(type) {
+		case *Ruby:
+			if imgs, err = appendEPUBImages(imgs, seen, v.Base); err != nil {
+				return nil, err
+			}
+		case *Layout:
+			if imgs, err = appendEPUBImages(imgs, seen, v.Nodes); err != nil {
+				return nil, err
+			}
+		case *Image:
+			name := strings.TrimPrefix(path.Clean("/"+v.File), "/")
+			if name != v.File {
+				return nil, errors.Errorf("invalid image file name: %s", v.File)
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			imgs = append(imgs, epubImage{
+				ID:        fmt.Sprintf("img%04d", len(imgs)+1),
+				Name:      name,
+				MediaType: imageMediaType(name),
+			})
+		}
+	}
+	return imgs, nil
+}
+
+func imageMediaType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".svg":
+		return "image/svg+xml"
+	case ".webp":
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
+
+func epubIdentifier(title, author string) string {
+	sum := sha1.Sum([]byte(title + "\x00" + author))
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml" />
+</rootfiles>
+</container>
+`
+
+func epubPackage(opt *epubOption, chapters []epubChapter, imgs []epubImage) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 4*1024))
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="BookId" xml:lang="ja">` + "\n")
+	buf.WriteString(`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(buf, `<dc:identifier id="BookId">%s</dc:identifier>`+"\n", html.EscapeString(opt.Identifier))
+	fmt.Fprintf(buf, "<dc:title>%s</dc:title>\n", html.EscapeString(opt.Title))
+	if opt.Author != "" {
+		fmt.Fprintf(buf, "<dc:creator>%s</dc:creator>\n", html.EscapeString(opt.Author))
+	}
+	buf.WriteString("<dc:language>ja</dc:language>\n")
+	fmt.Fprintf(buf, `<meta property="dcterms:modified">%s</meta>`+"\n", opt.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	buf.WriteString("</metadata>\n")
+
+	buf.WriteString("<manifest>\n")
+	buf.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav" />` + "\n")
+	buf.WriteString(`<item id="style" href="style.css" media-type="text/css" />` + "\n")
+	for i, c := range chapters {
+		fmt.Fprintf(buf, `<item id="chapter%04d" href="text/%s" media-type="application/xhtml+xml" />`+"\n", i+1, c.Name)
+	}
+	for _, img := range imgs {
+		fmt.Fprintf(buf, `<item id="%s" href="images/%s" media-type="%s" />`+"\n", img.ID, html.EscapeString(img.Name), img.MediaType)
+	}
+	buf.WriteString("</manifest>\n")
+
+	direction := "rtl"
+	if opt.Horizontal {
+		direction = "ltr"
+	}
+	fmt.Fprintf(buf, `<spine page-progression-direction="%s">`+"\n", direction)
+	for i, c := range chapters {
+		if spread := epubPageSpread(c.Break, opt.Horizontal); spread != "" {
+			fmt.Fprintf(buf, `<itemref idref="chapter%04d" properties="%s" />`+"\n", i+1, spread)
+			continue
+		}
+		fmt.Fprintf(buf, `<itemref idref="chapter%04d" />`+"\n", i+1)
+	}
+	buf.WriteString("</spine>\n")
+	buf.WriteString("</package>\n")
+	return buf.Bytes()
+}
+
+func epubNav(opt *epubOption, chapters []epubChapter) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<!DOCTYPE html>` + "\n")
+	buf.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="ja" lang="ja">` + "\n")
+	fmt.Fprintf(buf, "<head><meta charset=\"UTF-8\" /><title>%s</title></head>\n", html.EscapeString(opt.Title))
+	buf.WriteString("<body>\n")
+	buf.WriteString(`<nav epub:type="toc" id="toc">` + "\n<ol>\n")
+	for _, c := range chapters {
+		fmt.Fprintf(buf, `<li><a href="text/%s">%s</a></li>`+"\n", c.Name, html.EscapeString(c.Title))
+	}
+	buf.WriteString("</ol>\n</nav>\n")
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+func epubStyle(opt *epubOption) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 512))
+	if opt.Horizontal != true {
+		buf.WriteString("html { writing-mode: vertical-rl; -epub-writing-mode: vertical-rl; -webkit-writing-mode: vertical-rl; }\n")
+	}
+	buf.WriteString(".notes { font-size: smaller; }\n")
+	buf.WriteString(".illustration img { max-width: 100%; max-height: 100%; }\n")
+	buf.WriteString(".caption { display: block; font-size: smaller; }\n")
+	buf.WriteString(".tcy { text-combine-upright: all; -epub-text-combine: horizontal; -webkit-text-combine: horizontal; }\n")
+	buf.WriteString(".yokogumi { writing-mode: horizontal-tb; -epub-writing-mode: horizontal-tb; -webkit-writing-mode: horizontal-tb; }\n")
+	buf.WriteString(".warichu, .kogaki, .superscript, .subscript { font-size: smaller; }\n")
+	buf.WriteString(".keigakomi { border: 1px solid; }\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,183 @@
+package aozoraconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readZipFiles(t *testing.T, data []byte) ([]string, map[string]string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	names := make([]string, 0, len(zr.File))
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		names = append(names, f.Name)
+		files[f.Name] = string(b)
+	}
+	if zr.File[0].Method != zip.Store {
+		t.Errorf("mimetype should be stored")
+	}
+	return names, files
+}
+
+func TestWriteEPUB(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fig1234_01.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"田住生《たずみせい》",
+		"［＃挿絵（fig1234_01.png、横320×縦480）入る］",
+		"［＃挿絵（fig1234_01.png、横320×縦480）入る］",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+
+	out := bytes.NewBuffer(nil)
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := WriteEPUB(out, doc, NewDirImageSource(dir), WithEPUBModified(modified)); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	names, files := readZipFiles(t, out.Bytes())
+	if names[0] != "mimetype" || files["mimetype"] != "application/epub+zip" {
+		t.Errorf("first entry should be mimetype: %v", names)
+	}
+	if files["OEBPS/images/fig1234_01.png"] != "png" {
+		t.Errorf("image should be packaged: %v", names)
+	}
+	opf := files["OEBPS/content.opf"]
+	expects := []string{
+		"<dc:title>茗荷畠</dc:title>",
+		"<dc:creator>眞山青果</dc:creator>",
+		`<meta property="dcterms:modified">2020-01-02T03:04:05Z</meta>`,
+		`<item id="img0001" href="images/fig1234_01.png" media-type="image/png" />`,
+		`<spine page-progression-direction="rtl">`,
+	}
+	for _, expect := range expects {
+		if strings.Contains(opf, expect) != true {
+			t.Errorf("expect contains %s\nactual=%s", expect, opf)
+		}
+	}
+	if strings.Count(opf, "images/fig1234_01.png") != 1 {
+		t.Errorf("image should be unique: %s", opf)
+	}
+	chapter := files["OEBPS/text/chapter0001.xhtml"]
+	if strings.Contains(chapter, `<img src="../images/fig1234_01.png"`) != true {
+		t.Errorf("actual=%s", chapter)
+	}
+	if strings.Contains(files["OEBPS/style.css"], "vertical-rl") != true {
+		t.Errorf("default should be vertical")
+	}
+}
+
+func TestWriteEPUBWithoutImageSource(t *testing.T) {
+	doc, err := Parse(strings.NewReader("［＃挿絵（fig1234_01.png、横320×縦480）入る］"))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if err := WriteEPUB(io.Discard, doc, nil); err == nil {
+		t.Errorf("should be error")
+	}
+	doc, err = Parse(strings.NewReader("［＃挿絵（../fig1234_01.png）入る］"))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if err := WriteEPUB(io.Discard, doc, NewDirImageSource(t.TempDir())); err == nil {
+		t.Errorf("should be error")
+	}
+}
+
+func TestWriteEPUBChapters(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"［＃改ページ］",
+		"",
+		"［＃７字下げ］一［＃「一」は中見出し］",
+		"本文",
+		"［＃改丁］",
+		"二",
+		"［＃改ページ］",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := WriteEPUB(out, doc, nil); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	_, files := readZipFiles(t, out.Bytes())
+	for _, name := range []string{"chapter0001.xhtml", "chapter0002.xhtml", "chapter0003.xhtml"} {
+		if _, ok := files["OEBPS/text/"+name]; ok != true {
+			t.Errorf("%s should be exists", name)
+		}
+	}
+	if _, ok := files["OEBPS/text/chapter0004.xhtml"]; ok {
+		t.Errorf("empty section should be skipped")
+	}
+	if strings.Contains(files["OEBPS/text/chapter0002.xhtml"], "本文") != true {
+		t.Errorf("actual=%s", files["OEBPS/text/chapter0002.xhtml"])
+	}
+	nav := files["OEBPS/nav.xhtml"]
+	if strings.Contains(nav, `<a href="text/chapter0002.xhtml">一</a>`) != true {
+		t.Errorf("chapter title: %s", nav)
+	}
+	opf := files["OEBPS/content.opf"]
+	if strings.Contains(opf, `<itemref idref="chapter0003" properties="page-spread-left" />`) != true {
+		t.Errorf("page spread: %s", opf)
+	}
+}
+
+func TestWriteEPUBImageInLayout(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"fig1234_01.png", "fig1234_02.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	in := strings.Join([]string{
+		"本文［＃割り注］［＃挿絵（fig1234_01.png）入る］［＃割り注終わり］",
+		"［＃罫囲み］［＃挿絵（fig1234_02.png）入る］［＃罫囲み終わり］",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := WriteEPUB(out, doc, NewDirImageSource(dir)); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	names, files := readZipFiles(t, out.Bytes())
+	for _, name := range []string{"fig1234_01.png", "fig1234_02.png"} {
+		if files["OEBPS/images/"+name] != name {
+			t.Errorf("%s should be packaged: %v", name, names)
+		}
+		if strings.Contains(files["OEBPS/content.opf"], `href="images/`+name+`"`) != true {
+			t.Errorf("%s should be in manifest: %s", name, files["OEBPS/content.opf"])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type NotationErrorKind string
+
+const (
+	NotationUnclosedAnnotation NotationErrorKind = "unclosed-annotation"
+	NotationUnclosedRuby       NotationErrorKind = "unclosed-ruby"
+	NotationUnopenedRuby       NotationErrorKind = "unopened-ruby"
+	NotationInvalidGaiji       NotationErrorKind = "invalid-gaiji"
+)
+
+// Position is a position of a line in the input text
+type Position struct {
+	Line   int   // 1-based line number
+	Offset int64 // byte offset of the start of line
+}
+
+// NotationError is an error of Aozora Bunko notation,
+// Col is 1-based and counts characters, Offset is byte offset in the UTF-8 text read by Conv
+// (for Decode, the decoded text and not the Shift_JIS input)
+type NotationError struct {
+	Line   int
+	Col    int
+	Offset int64
+	Kind   NotationErrorKind
+	Text   string
+}
+
+func (e *NotationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Col, e.Kind, e.Text)
+}
+
+func newNotationError(pos Position, src string, index int, kind NotationErrorKind, text string) *NotationError {
+	return &NotationError{
+		Line:   pos.Line,
+		Col:    utf8.RuneCountInString(src[:index]) + 1,
+		Offset: pos.Offset + int64(index),
+		Kind:   kind,
+		Text:   text,
+	}
+}
@@ -3,14 +3,18 @@ package aozoraconv
 import (
 	"bytes"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
 var (
-	rubyIndex       = regexp.MustCompile(`([^｜]+?)([｜])([^《]+)《([^》]+)》`)
-	rubyQuote       = regexp.MustCompile(`([^《]+)《([^》]+)》`)
-	annoteOpenClose = regexp.MustCompile(`(［＃([^］]+)］)(.*)(［＃([^］]+)］)`)
-	annoteSingle    = regexp.MustCompile(`(［＃([^］]+)］)(.*)`)
-	repeatTwo       = regexp.MustCompile(`([^／]{2})(／＼)`)
+	rubyIndex    = regexp.MustCompile(`([^｜]+?)([｜])([^《]+)《([^》]+)》`)
+	rubyQuote    = regexp.MustCompile(`([^《]+)《([^》]+)》`)
+	annotation   = regexp.MustCompile(`［＃([^］]*)］`)
+	repeatTwo    = regexp.MustCompile(`([^／]{2})(／＼)`)
+	gaijiJis     = regexp.MustCompile(`※［＃[^］]*?、(?:第[1-4]水準)?([12]-[0-9]{1,2}-[0-9]{1,2})[^］]*］`)
+	gaijiUnicode = regexp.MustCompile(`※［＃[^］]*?、U\+([0-9A-Fa-f]{4,6}(?:\+[0-9A-Fa-f]{4,6})?)[^］]*］`)
+	gaijiCode    = regexp.MustCompile(`(?:^|、)(?:第[1-4]水準)?[12]-[0-9]{1,2}-[0-9]{1,2}|(?:^|、)U\+[0-9A-Fa-f]+`)
 )
 
 var (
@@ -22,14 +26,40 @@ type Escaper interface {
 	Escape(string) (out string, continues bool)
 }
 
+// LineEscaper is an Escaper that receives position of the line and reports errors
+// (e.g. *NotationError), Conv calls EscapeLine instead of Escape if implemented
+type LineEscaper interface {
+	Escaper
+	EscapeLine(pos Position, src string) (out string, continues bool, err error)
+}
+
+func escapeLine(e Escaper, pos Position, src string) (string, bool, error) {
+	if le, ok := e.(LineEscaper); ok {
+		return le.EscapeLine(pos, src)
+	}
+	out, ok := e.Escape(src)
+	return out, ok, nil
+}
+
 var (
 	_ Escaper = (*noopEscaper)(nil)
 	_ Escaper = (*rubyEscaper)(nil)
 	_ Escaper = (*annotationEscaper)(nil)
 	_ Escaper = (*repeatTwoEscaper)(nil)
 	_ Escaper = (*headerEscaper)(nil)
+	_ Escaper = (*gaijiEscaper)(nil)
+	_ Escaper = (*imageEscaper)(nil)
+	_ Escaper = (*kanbunEscaper)(nil)
+	_ Escaper = (*readingEscaper)(nil)
+	_ Escaper = (*widthEscaper)(nil)
+	_ Escaper = (*normalizeEscaper)(nil)
+	_ Escaper = (*ivsEscaper)(nil)
 	_ Escaper = (*bufferEscaper)(nil)
 	_ Escaper = (*chainEscaper)(nil)
+
+	_ LineEscaper = (*notationEscaper)(nil)
+	_ LineEscaper = (*bufferEscaper)(nil)
+	_ LineEscaper = (*chainEscaper)(nil)
 )
 
 type noopEscaper struct{}
@@ -60,26 +90,66 @@ func newRubyEscaper() *rubyEscaper {
 	}
 }
 
+// NewRubyEscaper returns Escaper that removes ruby (｜ and 《reading》) and keeps the base
+func NewRubyEscaper() Escaper {
+	return newRubyEscaper()
+}
+
 type annotationEscaper struct {
-	openclose *regexp.Regexp
-	single    *regexp.Regexp
+	re *regexp.Regexp
 }
 
 func (e *annotationEscaper) Escape(src string) (string, bool) {
-	if e.openclose.MatchString(src) {
-		return e.openclose.ReplaceAllString(src, "$3"), true
-	}
-	if e.single.MatchString(src) {
-		return e.single.ReplaceAllString(src, "$3"), true
+	if e.re.MatchString(src) {
+		return e.re.ReplaceAllString(src, ""), true
 	}
 	return src, true
 }
 
 func newAnnotationEscaper() *annotationEscaper {
 	return &annotationEscaper{
-		openclose: annoteOpenClose,
-		single:    annoteSingle,
+		re: annotation,
+	}
+}
+
+// NewAnnotationEscaper returns Escaper that removes annotations (［＃…］)
+func NewAnnotationEscaper() Escaper {
+	return newAnnotationEscaper()
+}
+
+type imageEscaper struct {
+	re *regexp.Regexp
+}
+
+func (e *imageEscaper) Escape(src string) (string, bool) {
+	if e.re.MatchString(src) {
+		return e.re.ReplaceAllStringFunc(src, func(s string) string {
+			m := imageAnnotation.FindStringSubmatch(e.re.FindStringSubmatch(s)[1])
+			if m == nil {
+				return s
+			}
+			description := m[2]
+			if description == "" {
+				description = "挿絵"
+			}
+			if m[1] != "" {
+				return "［" + description + "：" + m[1] + "］"
+			}
+			return "［" + description + "］"
+		}), true
 	}
+	return src, true
+}
+
+func newImageEscaper() *imageEscaper {
+	return &imageEscaper{
+		re: annotation,
+	}
+}
+
+// NewImageEscaper returns Escaper that replaces illustration annotations with placeholder (e.g. ［挿絵］)
+func NewImageEscaper() Escaper {
+	return newImageEscaper()
 }
 
 type repeatTwoEscaper struct {
@@ -99,6 +169,109 @@ func newRepeatTwoEscaper() *repeatTwoEscaper {
 	}
 }
 
+// NewRepeatTwoEscaper returns Escaper that expands repeat marks (／＼) into the repeated characters
+func NewRepeatTwoEscaper() Escaper {
+	return newRepeatTwoEscaper()
+}
+
+type gaijiEscaper struct {
+	reJis     *regexp.Regexp
+	reUnicode *regexp.Regexp
+}
+
+func (e *gaijiEscaper) Escape(src string) (string, bool) {
+	if e.reJis.MatchString(src) {
+		src = e.reJis.ReplaceAllStringFunc(src, func(s string) string {
+			m := e.reJis.FindStringSubmatch(s)
+			entry, err := ParseMenKuTen(m[1])
+			if err != nil {
+				return s
+			}
+			chr, err := entry.Unicode()
+			if err != nil {
+				return s
+			}
+			return chr
+		})
+	}
+	if e.reUnicode.MatchString(src) {
+		src = e.reUnicode.ReplaceAllStringFunc(src, func(s string) string {
+			m := e.reUnicode.FindStringSubmatch(s)
+			chr, ok := parseUnicodeSequence(m[1])
+			if ok != true {
+				return s
+			}
+			return chr
+		})
+	}
+	return src, true
+}
+
+func newGaijiEscaper() *gaijiEscaper {
+	return &gaijiEscaper{
+		reJis:     gaijiJis,
+		reUnicode: gaijiUnicode,
+	}
+}
+
+// NewGaijiEscaper returns Escaper that replaces gaiji annotations (※［＃…］) with Unicode characters
+func NewGaijiEscaper() Escaper {
+	return newGaijiEscaper()
+}
+
+// notationEscaper checks notations of a line and reports *NotationError
+type notationEscaper struct{}
+
+func (e *notationEscaper) Escape(src string) (string, bool) {
+	return src, true
+}
+
+func (e *notationEscaper) EscapeLine(pos Position, src string) (string, bool, error) {
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, notationGaijiOpen):
+			body, n, ok := annotationBody(rest[len(notationGaijiOpen):])
+			if ok != true {
+				return src, false, newNotationError(pos, src, i, NotationUnclosedAnnotation, "※［＃ is not closed")
+			}
+			if g := newGaiji(body); g.Char == "" && gaijiCode.MatchString(g.Note) {
+				return src, false, newNotationError(pos, src, i, NotationInvalidGaiji, "gaiji can not be resolved: "+body)
+			}
+			i += len(notationGaijiOpen) + n
+			continue
+		case strings.HasPrefix(rest, notationAnnotationOpen):
+			_, n, ok := annotationBody(rest[len(notationAnnotationOpen):])
+			if ok != true {
+				return src, false, newNotationError(pos, src, i, NotationUnclosedAnnotation, "［＃ is not closed")
+			}
+			i += len(notationAnnotationOpen) + n
+			continue
+		case strings.HasPrefix(rest, notationRubyOpen):
+			n := strings.Index(rest, notationRubyClose)
+			if n < 0 {
+				return src, false, newNotationError(pos, src, i, NotationUnclosedRuby, "《 is not closed")
+			}
+			i += n + len(notationRubyClose)
+			continue
+		case strings.HasPrefix(rest, notationRubyClose):
+			return src, false, newNotationError(pos, src, i, NotationUnopenedRuby, "》 without 《")
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		i += size
+	}
+	return src, true, nil
+}
+
+func newNotationEscaper() *notationEscaper {
+	return &notationEscaper{}
+}
+
+// NewNotationEscaper returns Escaper that reports malformed notation as *NotationError, the text is not changed
+func NewNotationEscaper() Escaper {
+	return newNotationEscaper()
+}
+
 type headerEscaper struct {
 	re *regexp.Regexp
 }
@@ -117,6 +290,11 @@ func newHeaderEscaper() *headerEscaper {
 	}
 }
 
+// NewHeaderEscaper returns Escaper that removes header (title and notation explanation) of buffered text
+func NewHeaderEscaper() Escaper {
+	return newHeaderEscaper()
+}
+
 type bufferEscaper struct {
 	buf          *bytes.Buffer
 	he           Escaper
@@ -130,46 +308,51 @@ type bufferEscaper struct {
 }
 
 func (e *bufferEscaper) Escape(src string) (string, bool) {
+	out, ok, _ := e.EscapeLine(Position{}, src)
+	return out, ok
+}
+
+func (e *bufferEscaper) EscapeLine(pos Position, src string) (string, bool, error) {
 	if e.foundHeader != true {
 		e.buf.WriteString(src)
 		out, ok := e.he.Escape(e.buf.String())
 		if ok != true {
-			return "", false
+			return "", false, nil
 		}
 		e.foundHeader = true
 		e.buf.Reset()
-		return out, true
+		return out, true, nil
 	}
 
 	if e.foundFooter {
-		return "", false
+		return "", false, nil
 	}
 	if src == "\r\n" {
 		if e.footerStart1 != true {
 			e.footerStart1 = true
-			return src, true
+			return src, true, nil
 		}
 		if e.footerStart2 != true {
 			e.footerStart2 = true
-			return src, true
+			return src, true, nil
 		}
 		if e.footerStart3 != true {
 			e.footerStart3 = true
-			return src, true
+			return src, true, nil
 		}
 	}
 
 	if e.footerStart1 && e.footerStart2 && e.footerStart3 {
 		if e.footer.MatchString(src) {
 			e.foundFooter = true
-			return "", false
+			return "", false, nil
 		}
 	} else {
 		e.footerStart1 = false
 		e.footerStart2 = false
 		e.footerStart3 = false
 	}
-	return e.ce.Escape(src)
+	return escapeLine(e.ce, pos, src)
 }
 
 func newBufferEscaper(h Escaper, chain []Escaper) *bufferEscaper {
@@ -191,36 +374,64 @@ type chainEscaper struct {
 }
 
 func (e *chainEscaper) Escape(src string) (string, bool) {
+	out, ok, _ := e.EscapeLine(Position{}, src)
+	return out, ok
+}
+
+func (e *chainEscaper) EscapeLine(pos Position, src string) (string, bool, error) {
 	out := src
 	for _, c := range e.chain {
-		esc, ok := c.Escape(out)
+		esc, ok, err := escapeLine(c, pos, out)
+		if err != nil {
+			return out, false, err
+		}
 		if ok != true {
-			return out, false
+			return out, false, nil
 		}
 		out = esc
 	}
-	return out, true
+	return out, true, nil
 }
 
-func NewEscape(opt *option) Escaper {
-	if opt.Header == nil && opt.Ruby == nil && opt.Annotation == nil {
-		return new(noopEscaper)
-	}
+// NewEscaper returns escaper chain built from options
+func NewEscaper(opts ...OptionFunc) Escaper {
+	return NewEscape(newOption(opts...))
+}
 
-	chain := make([]Escaper, 0, 2)
-	if opt.Ruby != nil {
-		chain = append(chain, opt.Ruby)
-	}
-	if opt.Annotation != nil {
-		chain = append(chain, opt.Annotation)
+func NewEscape(opt *option) Escaper {
+	stages := []struct {
+		stage EscaperStage
+		esc   Escaper
+	}{
+		{StageNotation, opt.Notation},
+		{StageNormalize, opt.Normalize},
+		{StageIVS, opt.IVS},
+		{StageKanbun, opt.Kanbun},
+		{StageGaiji, opt.Gaiji},
+		{StageModernize, opt.Modernize},
+		{StageWidth, opt.Width},
+		{StageReading, opt.Reading},
+		{StageRuby, opt.Ruby},
+		{StageImage, opt.Image},
+		{StageAnnotation, opt.Annotation},
+		{StageRepeatTwo, opt.RepeatTwo},
 	}
-	if opt.RepeatTwo != nil {
-		chain = append(chain, opt.RepeatTwo)
+	chain := make([]Escaper, 0, len(stages)+len(opt.Escapers))
+	for _, s := range stages {
+		chain = append(chain, opt.Before[s.stage]...)
+		if s.esc != nil {
+			chain = append(chain, s.esc)
+		}
+		chain = append(chain, opt.After[s.stage]...)
 	}
+	chain = append(chain, opt.Escapers...)
 
 	if opt.Header != nil {
 		return newBufferEscaper(opt.Header, chain)
 	}
+	if len(chain) < 1 {
+		return new(noopEscaper)
+	}
 
 	return &chainEscaper{chain}
 }
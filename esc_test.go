@@ -1,6 +1,7 @@
 package aozoraconv
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -70,6 +71,29 @@ func TestEscaperAnnotation(t *testing.T) {
 	})
 }
 
+func TestEscaperAnnotationImage(t *testing.T) {
+	t.Run("drop", func(tt *testing.T) {
+		e := newAnnotationEscaper()
+		out, ok := e.Escape(`［＃挿絵（fig1234_01.png、横320×縦480）入る］停車場の前［＃「前」に傍点］で［＃挿絵（fig1234_02.png、横320×縦480）入る］待つ`)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != "停車場の前で待つ" {
+			tt.Errorf("escape [#] actual=%s", out)
+		}
+	})
+	t.Run("placeholder", func(tt *testing.T) {
+		e := NewEscape(newOption(WithImagePlaceholder(), WithoutAnnotation()))
+		out, ok := e.Escape(`［＃挿絵（fig1234_01.png、横320×縦480）入る］停車場［＃「駅前」のキャプション付きの図（fig1234_02.png）入る］待つ`)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != "［挿絵］停車場［図：駅前］待つ" {
+			tt.Errorf("placeholder actual=%s", out)
+		}
+	})
+}
+
 func TestEscaperRepeatTwo(t *testing.T) {
 	t.Run("single", func(tt *testing.T) {
 		e := newRepeatTwoEscaper()
@@ -83,6 +107,51 @@ func TestEscaperRepeatTwo(t *testing.T) {
 	})
 }
 
+func TestEscaperGaiji(t *testing.T) {
+	t.Run("jis x 0213", func(tt *testing.T) {
+		e := newGaijiEscaper()
+		out, ok := e.Escape(`※［＃「足へん＋宛」、第3水準1-92-36］を※［＃「てへん＋臿」、第4水準2-13-28］`)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != "踠を揷" {
+			tt.Errorf("escape gaiji actual=%s", out)
+		}
+	})
+	t.Run("unicode", func(tt *testing.T) {
+		e := newGaijiEscaper()
+		out, ok := e.Escape(`※［＃「口＋世」、U+546D、13-3］`)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != "呭" {
+			tt.Errorf("escape gaiji actual=%s", out)
+		}
+	})
+	t.Run("not representable", func(tt *testing.T) {
+		e := newGaijiEscaper()
+		in := `※［＃「てへん＋劣」、206-4］`
+		out, ok := e.Escape(in)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != in {
+			tt.Errorf("passthrough actual=%s", out)
+		}
+	})
+}
+
+func TestNewEscapeRepeatTwoOnly(t *testing.T) {
+	e := NewEscape(newOption(WithoutRepeatTwo()))
+	out, ok := e.Escape(`頭をフラ／＼`)
+	if ok != true {
+		t.Errorf("always true")
+	}
+	if out != "頭をフラフラ" {
+		t.Errorf("escape repeat2 actual=%s", out)
+	}
+}
+
 var (
 	testHeader1 = `茗荷畠
 眞山青果
@@ -109,6 +178,49 @@ var (
 `
 )
 
+type replaceEscaper struct {
+	old, new string
+}
+
+func (e *replaceEscaper) Escape(src string) (string, bool) {
+	return strings.ReplaceAll(src, e.old, e.new), true
+}
+
+func TestNewEscaperCustom(t *testing.T) {
+	t.Run("append to the end", func(tt *testing.T) {
+		e := NewEscaper(
+			WithoutRuby(),
+			WithEscaper(&replaceEscaper{"停車場", "駅"}),
+			WithEscapers(&replaceEscaper{"駅", "ステーション"}, &replaceEscaper{"晩", "夜"}),
+		)
+		out, ok := e.Escape(`晩｜停車場《ていしゃば》`)
+		if ok != true {
+			tt.Errorf("always true")
+		}
+		if out != "夜ステーション" {
+			tt.Errorf("actual=%s", out)
+		}
+	})
+	t.Run("before and after stage", func(tt *testing.T) {
+		e := NewEscaper(
+			WithoutRuby(),
+			WithEscaperAfter(StageRuby, &replaceEscaper{"停車場", "駅"}),
+			WithEscaperBefore(StageRuby, &replaceEscaper{"《ていしゃば》", "《えき》"}),
+			WithEscaperBefore(StageRuby, &replaceEscaper{"えき", "ステーション"}),
+		)
+		out, _ := e.Escape(`晩｜停車場《ていしゃば》`)
+		if out != "晩駅" {
+			tt.Errorf("actual=%s", out)
+		}
+
+		e = NewEscaper(WithEscaperBefore(StageRuby, &replaceEscaper{"ていしゃば", "えき"}))
+		out, _ = e.Escape(`晩｜停車場《ていしゃば》`)
+		if out != "晩｜停車場《えき》" {
+			tt.Errorf("escaper is added without built-in: actual=%s", out)
+		}
+	})
+}
+
 func TestEscaperHeader(t *testing.T) {
 	t.Run("header1", func(tt *testing.T) {
 		e := newHeaderEscaper()
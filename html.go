@@ -0,0 +1,344 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type HTMLOptionFunc func(*htmlOption)
+
+type htmlOption struct {
+	Title       string
+	ImagePrefix string
+	Stylesheets []string
+}
+
+// WithHTMLTitle sets title of html document (default: title in header)
+func WithHTMLTitle(title string) HTMLOptionFunc {
+	return func(opt *htmlOption) {
+		opt.Title = title
+	}
+}
+
+// WithImagePrefix sets prefix of image src (e.g. "images/")
+func WithImagePrefix(prefix string) HTMLOptionFunc {
+	return func(opt *htmlOption) {
+		opt.ImagePrefix = prefix
+	}
+}
+
+// WithStylesheet adds stylesheet link
+func WithStylesheet(href string) HTMLOptionFunc {
+	return func(opt *htmlOption) {
+		opt.Stylesheets = append(opt.Stylesheets, href)
+	}
+}
+
+func newHTMLOption(funcs ...HTMLOptionFunc) *htmlOption {
+	opt := &htmlOption{
+		Title:       "",
+		ImagePrefix: "",
+		Stylesheets: nil,
+	}
+	for _, fn := range funcs {
+		fn(opt)
+	}
+	return opt
+}
+
+// RenderHTML renders document as XHTML
+func RenderHTML(w io.Writer, doc *Document, opts ...HTMLOptionFunc) error {
+	opt := newHTMLOption(opts...)
+	if opt.Title == "" {
+		opt.Title, _ = doc.Title()
+	}
+	if err := writeHTMLHeader(w, opt); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeHTMLLines(w, doc.Lines, opt); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeHTMLFooter(w); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func writeHTMLHeader(w io.Writer, opt *htmlOption) error {
+	buf := bytes.NewBuffer(make([]byte, 0, 512))
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<!DOCTYPE html>` + "\n")
+	buf.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="ja" lang="ja">` + "\n")
+	buf.WriteString("<head>\n")
+	buf.WriteString(`<meta charset="UTF-8" />` + "\n")
+	fmt.Fprintf(buf, "<title>%s</title>\n", html.EscapeString(opt.Title))
+	for _, href := range opt.Stylesheets {
+		fmt.Fprintf(buf, `<link rel="stylesheet" type="text/css" href="%s" />`+"\n", html.EscapeString(href))
+	}
+	buf.WriteString("</head>\n")
+	buf.WriteString("<body>\n")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func writeHTMLFooter(w io.Writer) error {
+	if _, err := io.WriteString(w, "</body>\n</html>\n"); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func writeHTMLLines(w io.Writer, lines []*Line, opt *htmlOption) error {
+	buf := bytes.NewBuffer(make([]byte, 0, 4*1024))
+	var pending *PageBreak
+	var active []string
+	for _, l := range lines {
+		if pb, ok := onlyPageBreak(l); ok {
+			pending = pb
+			continue
+		}
+		if onlyLayoutMarker(l) {
+			active = updateLayouts(active, l.Nodes)
+			continue
+		}
+
+		buf.Reset()
+		writeHTMLLineStart(buf, pending, active)
+		pending = nil
+		if len(l.Nodes) < 1 {
+			buf.WriteString("<br />")
+		}
+		writeHTMLLineNodes(buf, l.Nodes, active, opt)
+		buf.WriteString("</div>\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return errors.WithStack(err)
+		}
+		active = updateLayouts(active, l.Nodes)
+	}
+	return nil
+}
+
+// onlyPageBreak returns last page break if line has only page breaks
+func onlyPageBreak(l *Line) (*PageBreak, bool) {
+	var pb *PageBreak
+	for _, n := range l.Nodes {
+		v, ok := n.(*PageBreak)
+		if ok != true {
+			return nil, false
+		}
+		pb = v
+	}
+	return pb, pb != nil
+}
+
+// onlyLayoutMarker reports whether line has only starts and ends of layout ranges
+func onlyLayoutMarker(l *Line) bool {
+	for _, n := range l.Nodes {
+		switch n.(type) {
+		case *LayoutStart, *LayoutEnd:
+			continue
+		}
+		return false
+	}
+	return 0 < len(l.Nodes)
+}
+
+// updateLayouts returns layouts active after nodes
+func updateLayouts(active []string, nodes []Node) []string {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *LayoutStart:
+			active = append(active, v.Kind)
+		case *LayoutEnd:
+			for i := len(active) - 1; 0 <= i; i -= 1 {
+				if active[i] == v.Kind {
+					active = append(active[:i:i], active[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return active
+}
+
+func writeHTMLLineStart(buf *bytes.Buffer, pb *PageBreak, active []string) {
+	classes := []string{"line"}
+	styles := []string{}
+	if pb != nil {
+		switch pb.Kind {
+		case PageBreakRecto:
+			classes = append(classes, "page-break")
+			styles = append(styles, "page-break-before: right; break-before: recto;")
+		case PageBreakSpread:
+			classes = append(classes, "page-break")
+			styles = append(styles, "page-break-before: left; break-before: verso;")
+		case PageBreakColumn:
+			classes = append(classes, "column-break")
+			styles = append(styles, "break-before: column;")
+		default:
+			classes = append(classes, "page-break")
+			styles = append(styles, "page-break-before: always; break-before: page;")
+		}
+	}
+	for _, kind := range active {
+		s := htmlLayoutStyleOf(kind)
+		classes = append(classes, s.Class)
+		if s.Style != "" {
+			styles = append(styles, s.Style)
+		}
+	}
+	fmt.Fprintf(buf, `<div class="%s"`, strings.Join(classes, " "))
+	if 0 < len(styles) {
+		fmt.Fprintf(buf, ` style="%s"`, strings.Join(styles, " "))
+	}
+	buf.WriteString(">")
+}
+
+type htmlLayoutStyle struct {
+	Tag   string
+	Class string
+	Style string
+}
+
+var htmlLayoutStyles = map[string]htmlLayoutStyle{
+	LayoutTatechuyoko: {"span", "tcy", "text-combine-upright: all; -webkit-text-combine: horizontal;"},
+	LayoutWarichu:     {"span", "warichu", ""},
+	LayoutYokogumi:    {"span", "yokogumi", "writing-mode: horizontal-tb;"},
+	LayoutKeigakomi:   {"span", "keigakomi", "border: 1px solid;"},
+	LayoutSuperscript: {"sup", "superscript", ""},
+	LayoutSubscript:   {"sub", "subscript", ""},
+	LayoutKogaki:      {"span", "kogaki", "font-size: smaller;"},
+}
+
+func htmlLayoutStyleOf(kind string) htmlLayoutStyle {
+	if s, ok := htmlLayoutStyles[kind]; ok {
+		return s
+	}
+	return htmlLayoutStyle{"span", "layout", ""}
+}
+
+func writeHTMLLayoutOpen(buf *bytes.Buffer, kind string) {
+	s := htmlLayoutStyleOf(kind)
+	fmt.Fprintf(buf, `<%s class="%s"`, s.Tag, s.Class)
+	if s.Style != "" {
+		fmt.Fprintf(buf, ` style="%s"`, s.Style)
+	}
+	buf.WriteString(">")
+}
+
+func writeHTMLLayoutClose(buf *bytes.Buffer, kind string) {
+	fmt.Fprintf(buf, "</%s>", htmlLayoutStyleOf(kind).Tag)
+}
+
+// writeHTMLLineNodes writes nodes of a line, layout ranges which start in
+// the line are closed at the end of the line and at page breaks
+func writeHTMLLineNodes(buf *bytes.Buffer, nodes []Node, active []string, opt *htmlOption) {
+	opened := make([]string, 0, 2)
+	closeAll := func() {
+		for i := len(opened) - 1; 0 <= i; i -= 1 {
+			writeHTMLLayoutClose(buf, opened[i])
+		}
+	}
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *LayoutStart:
+			writeHTMLLayoutOpen(buf, v.Kind)
+			opened = append(opened, v.Kind)
+			active = append(active[:len(active):len(active)], v.Kind)
+		case *LayoutEnd:
+			if 0 < len(opened) && opened[len(opened)-1] == v.Kind {
+				writeHTMLLayoutClose(buf, v.Kind)
+				opened = opened[:len(opened)-1]
+			}
+			active = updateLayouts(active[:len(active):len(active)], []Node{v})
+		case *PageBreak:
+			closeAll()
+			buf.WriteString("</div>\n")
+			writeHTMLLineStart(buf, v, active)
+			opened = opened[:0]
+		default:
+			writeHTMLNodes(buf, []Node{n}, opt)
+		}
+	}
+	closeAll()
+}
+
+func writeHTMLNodes(buf *bytes.Buffer, nodes []Node, opt *htmlOption) {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			buf.WriteString(html.EscapeString(v.Value))
+		case *Ruby:
+			buf.WriteString("<ruby><rb>")
+			writeHTMLNodes(buf, v.Base, opt)
+			buf.WriteString("</rb><rp>（</rp><rt>")
+			buf.WriteString(html.EscapeString(v.Reading))
+			buf.WriteString("</rt><rp>）</rp></ruby>")
+		case *Gaiji:
+			if v.Char != "" {
+				fmt.Fprintf(buf, `<span class="gaiji" title="%s">%s</span>`, html.EscapeString(v.Description), html.EscapeString(v.Char))
+			} else {
+				fmt.Fprintf(buf, `※<span class="notes">［＃%s］</span>`, html.EscapeString(gaijiBodyString(v)))
+			}
+		case *Image:
+			writeHTMLImage(buf, v, opt)
+		case *PageBreak:
+			buf.WriteString("</div>\n")
+			writeHTMLLineStart(buf, v, nil)
+		case *Layout:
+			writeHTMLLayoutOpen(buf, v.Kind)
+			if v.Kind == LayoutWarichu {
+				buf.WriteString("（")
+				writeHTMLNodes(buf, v.Nodes, opt)
+				buf.WriteString("）")
+			} else {
+				writeHTMLNodes(buf, v.Nodes, opt)
+			}
+			writeHTMLLayoutClose(buf, v.Kind)
+		case *Kanbun:
+			if v.Okurigana != "" {
+				fmt.Fprintf(buf, `<sup class="okurigana">%s</sup>`, html.EscapeString(v.Okurigana))
+			}
+			if v.Kaeriten != "" {
+				fmt.Fprintf(buf, `<sub class="kaeriten">%s</sub>`, html.EscapeString(v.Kaeriten))
+			}
+		case *Annotation:
+			fmt.Fprintf(buf, `<span class="notes">［＃%s］</span>`, html.EscapeString(v.Body))
+		}
+	}
+}
+
+func writeHTMLImage(buf *bytes.Buffer, img *Image, opt *htmlOption) {
+	alt := img.Caption
+	if alt == "" {
+		alt = img.Description
+	}
+	buf.WriteString(`<span class="illustration">`)
+	fmt.Fprintf(buf, `<img src="%s" alt="%s"`, html.EscapeString(opt.ImagePrefix+img.File), html.EscapeString(alt))
+	if 0 < img.Width && 0 < img.Height {
+		fmt.Fprintf(buf, ` width="%d" height="%d"`, img.Width, img.Height)
+	}
+	buf.WriteString(" />")
+	if img.Caption != "" {
+		fmt.Fprintf(buf, `<span class="caption">%s</span>`, html.EscapeString(img.Caption))
+	}
+	buf.WriteString("</span>")
+}
+
+// gaijiBodyString returns body of gaiji annotation (「description」、note)
+func gaijiBodyString(g *Gaiji) string {
+	if g.Description == "" {
+		return g.Note
+	}
+	if g.Note == "" {
+		return "「" + g.Description + "」"
+	}
+	return "「" + g.Description + "」、" + g.Note
+}
@@ -0,0 +1,96 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"晩｜停車場《ステーション》で<待つ>",
+		"［＃挿絵（fig1234_01.png、横320×縦480）入る］",
+		"［＃「駅前」のキャプション付きの図（fig1234_02.png）入る］",
+		"［＃石鏃二つの図（fig42154_01.png、横321×縦123）入る］",
+		"※［＃「足へん＋宛」、第3水準1-92-36］※［＃「てへん＋劣」、206-4］［＃「二」は中見出し］",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := RenderHTML(out, doc, WithImagePrefix("img/")); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expects := []string{
+		"<title>茗荷畠</title>",
+		`<div class="line"><br /></div>`,
+		`<div class="line">晩<ruby><rb>停車場</rb><rp>（</rp><rt>ステーション</rt><rp>）</rp></ruby>で&lt;待つ&gt;</div>`,
+		`<img src="img/fig1234_01.png" alt="挿絵" width="320" height="480" />`,
+		`<img src="img/fig1234_02.png" alt="駅前" /><span class="caption">駅前</span>`,
+		`<img src="img/fig42154_01.png" alt="石鏃二つの図" width="321" height="123" />`,
+		`<span class="gaiji" title="足へん＋宛">踠</span>`,
+		`※<span class="notes">［＃「てへん＋劣」、206-4］</span>`,
+		`<span class="notes">［＃「二」は中見出し］</span>`,
+	}
+	for _, expect := range expects {
+		if strings.Contains(out.String(), expect) != true {
+			t.Errorf("expect contains %s\nactual=%s", expect, out.String())
+		}
+	}
+}
+
+func TestRenderHTMLPageBreak(t *testing.T) {
+	in := strings.Join([]string{
+		"一",
+		"［＃改ページ］",
+		"二",
+		"前［＃改段］後",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := RenderHTML(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := `<div class="line">一</div>
+<div class="line page-break" style="page-break-before: always; break-before: page;">二</div>
+<div class="line">前</div>
+<div class="line column-break" style="break-before: column;">後</div>
+`
+	if strings.Contains(out.String(), expect) != true {
+		t.Errorf("expect contains %s\nactual=%s", expect, out.String())
+	}
+}
+
+func TestRenderHTMLLayout(t *testing.T) {
+	in := strings.Join([]string{
+		"昭和10［＃「10」は縦中横］年［＃割り注］注記［＃割り注終わり］Ｈ２［＃「２」は下付き小文字］Ｏ",
+		"［＃ここから横組み］",
+		"E=mc2",
+		"［＃ここで横組み終わり］",
+		"前［＃罫囲み］後",
+		"次",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := RenderHTML(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := `<div class="line">昭和<span class="tcy" style="text-combine-upright: all; -webkit-text-combine: horizontal;">10</span>年<span class="warichu">（注記）</span>Ｈ<sub class="subscript">２</sub>Ｏ</div>
+<div class="line yokogumi" style="writing-mode: horizontal-tb;">E=mc2</div>
+<div class="line">前<span class="keigakomi" style="border: 1px solid;">後</span></div>
+<div class="line keigakomi" style="border: 1px solid;">次</div>
+`
+	if strings.Contains(out.String(), expect) != true {
+		t.Errorf("expect contains %s\nactual=%s", expect, out.String())
+	}
+}
@@ -0,0 +1,95 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// isVariationSelector reports whether r is a variation selector of
+// standardized variants (U+FE00-U+FE0F) or ideographic variation sequences (U+E0100-U+E01EF)
+func isVariationSelector(r rune) bool {
+	return (0xfe00 <= r && r <= 0xfe0f) || (0xe0100 <= r && r <= 0xe01ef)
+}
+
+// variantIdeograph returns CJK compatibility ideograph of the standardized variant
+// (U+585A U+FE00 -> 塚 U+FA10), selectors are assigned in the order of code points
+// of compatibility ideographs as StandardizedVariants.txt
+func variantIdeograph(base, vs rune) (rune, bool) {
+	if vs < 0xfe00 || 0xfe0f < vs {
+		return base, false
+	}
+	compat := compatIdeographs(base)
+	i := int(vs - 0xfe00)
+	if len(compat) <= i {
+		return base, false
+	}
+	return compat[i], true
+}
+
+// parseUnicodeSequence parses code points of gaiji notation ("845B" or "845B+E0101")
+func parseUnicodeSequence(s string) (string, bool) {
+	buf := new(strings.Builder)
+	for _, code := range strings.Split(s, "+") {
+		r, err := strconv.ParseUint(code, 16, 32)
+		if err != nil || utf8.ValidRune(rune(r)) != true {
+			return "", false
+		}
+		buf.WriteRune(rune(r))
+	}
+	return buf.String(), true
+}
+
+// ivsEscaper replaces variation sequences with CJK compatibility ideographs if
+// the variant is distinguishable in JIS X 0213, otherwise selectors are removed
+// or the sequences are replaced with gaiji notation (※［＃「葛」、U+845B+E0101］)
+type ivsEscaper struct {
+	gaiji bool
+}
+
+func (e *ivsEscaper) Escape(src string) (string, bool) {
+	buf := new(strings.Builder)
+	buf.Grow(len(src))
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		if isVariationSelector(r) {
+			// selector without base character
+			i += size
+			continue
+		}
+		vs, n := utf8.DecodeRuneInString(src[i+size:])
+		if isVariationSelector(vs) != true {
+			buf.WriteString(src[i : i+size])
+			i += size
+			continue
+		}
+		buf.WriteString(e.variant(r, vs))
+		i += size + n
+	}
+	return buf.String(), true
+}
+
+func (e *ivsEscaper) variant(base, vs rune) string {
+	if c, ok := variantIdeograph(base, vs); ok {
+		if _, ok := lookupJis(c); ok {
+			return string(c)
+		}
+	}
+	if e.gaiji {
+		return fmt.Sprintf("%s「%c」、U+%04X+%04X%s", notationGaijiOpen, base, base, vs, notationBracketClose)
+	}
+	return string(base)
+}
+
+func newIVSEscaper(gaiji bool) *ivsEscaper {
+	return &ivsEscaper{
+		gaiji: gaiji,
+	}
+}
+
+// NewIVSEscaper returns Escaper that maps variation sequences into JIS X 0213 characters
+// or removes selectors, sequences are replaced with gaiji notation if gaiji is true
+func NewIVSEscaper(gaiji bool) Escaper {
+	return newIVSEscaper(gaiji)
+}
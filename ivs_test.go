@@ -0,0 +1,57 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIVSEscaper(t *testing.T) {
+	tests := []struct {
+		in     string
+		gaiji  bool
+		expect string
+	}{
+		{"塚︀と塚", false, "塚と塚"},
+		{"葛\U000E0101飾", false, "葛飾"},
+		{"葛\U000E0101飾", true, "※［＃「葛」、U+845B+E0101］飾"},
+		{"\U000E0100a塚︀", true, "a塚"},
+	}
+	for _, tc := range tests {
+		actual, ok := newIVSEscaper(tc.gaiji).Escape(tc.in)
+		if ok != true {
+			t.Errorf("always true")
+		}
+		if actual != tc.expect {
+			t.Errorf("%+q: expect=%+q actual=%+q", tc.in, tc.expect, actual)
+		}
+	}
+}
+
+func TestResolveIVSGaiji(t *testing.T) {
+	in := "※［＃「葛」、U+845B+E0101］飾"
+	actual, _ := newGaijiEscaper().Escape(in)
+	if expect := "葛\U000E0101飾"; actual != expect {
+		t.Errorf("expect=%+q actual=%+q", expect, actual)
+	}
+	if g := newGaiji("「葛」、U+845B+E0101"); g.Char != "葛\U000E0101" {
+		t.Errorf("gaiji node: actual=%+q", g.Char)
+	}
+}
+
+func TestEncodeIVS(t *testing.T) {
+	expect := bytes.NewBuffer(nil)
+	if err := Encode(expect, strings.NewReader("葛飾\r\n")); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if err := Encode(bytes.NewBuffer(nil), strings.NewReader("葛\U000E0101飾\r\n")); err == nil {
+		t.Errorf("variation selector is not in Shift_JIS without WithIVS")
+	}
+	actual := bytes.NewBuffer(nil)
+	if err := Encode(actual, strings.NewReader("葛\U000E0101飾\r\n"), WithIVS()); err != nil {
+		t.Fatalf("variation selector should be removed: %+v", err)
+	}
+	if bytes.Equal(expect.Bytes(), actual.Bytes()) != true {
+		t.Errorf("expect=%x actual=%x", expect.Bytes(), actual.Bytes())
+	}
+}
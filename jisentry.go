@@ -0,0 +1,99 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	menKuTenLevel = regexp.MustCompile(`^第([1-4])水準(.+)$`)
+)
+
+// String returns men-ku-ten notation (e.g. "1-85-9")
+func (j JisEntry) String() string {
+	return fmt.Sprintf("%d-%d-%d", j.Men, j.Ku, j.Ten)
+}
+
+// Level returns JIS X 0213 level (1..4) of this character
+// level 1 and 2 are JIS X 0208, level 3 is plane 1 extension, level 4 is plane 2
+func (j JisEntry) Level() int {
+	if j.Men == 2 {
+		return 4
+	}
+	if Is0208(int(j.Men), int(j.Ku), int(j.Ten)) != true {
+		return 3
+	}
+	if 48 <= j.Ku {
+		return 2
+	}
+	return 1
+}
+
+// AozoraNotation returns men-ku-ten notation with level used in gaiji annotation (e.g. "第3水準1-85-9")
+func (j JisEntry) AozoraNotation() string {
+	return fmt.Sprintf("第%d水準%s", j.Level(), j.String())
+}
+
+// ShiftJIS2004Bytes returns Shift_JIS-2004 byte strings (2 byte)
+func (j JisEntry) ShiftJIS2004Bytes() ([]byte, error) {
+	b, err := MenKuTen2Sjis2004(int(j.Men), int(j.Ku), int(j.Ten))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// EUCBytes returns EUC-JIS-2004 byte strings (2 byte in plane 1, 3 byte in plane 2)
+func (j JisEntry) EUCBytes() ([]byte, error) {
+	if err := j.validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	c1, c2 := uint8(j.Ku)+0xa0, uint8(j.Ten)+0xa0
+	if j.Men == 2 {
+		return []byte{0x8f, c1, c2}, nil
+	}
+	return []byte{c1, c2}, nil
+}
+
+// Unicode returns a string of this character
+func (j JisEntry) Unicode() (string, error) {
+	return Jis2Uni(int(j.Men), int(j.Ku), int(j.Ten))
+}
+
+func (j JisEntry) validate() error {
+	if j.Men < 1 || j.Men > 2 || j.Ku < 1 || j.Ku > 94 || j.Ten < 1 || j.Ten > 94 {
+		return errors.Errorf("invalid men-ku-ten: %s", j.String())
+	}
+	return nil
+}
+
+// GaijiAnnotation returns gaiji annotation in Aozora Bunko format (e.g. "※［＃「足へん＋宛」、第3水準1-92-36］")
+func (j JisEntry) GaijiAnnotation(description string) string {
+	return fmt.Sprintf("※［＃「%s」、%s］", description, j.AozoraNotation())
+}
+
+// ParseMenKuTen returns JisEntry from men-ku-ten notation ("1-85-9" or "第3水準1-85-9")
+func ParseMenKuTen(s string) (JisEntry, error) {
+	level := 0
+	if m := menKuTenLevel.FindStringSubmatch(s); m != nil {
+		level = int(m[1][0] - '0')
+		s = m[2]
+	}
+	var men, ku, ten int
+	if n, err := fmt.Sscanf(s, "%d-%d-%d", &men, &ku, &ten); err != nil || n != 3 {
+		return JisEntry{0, 0, 0}, errors.Errorf("invalid men-ku-ten notation: %s", s)
+	}
+	if fmt.Sprintf("%d-%d-%d", men, ku, ten) != s {
+		return JisEntry{0, 0, 0}, errors.Errorf("invalid men-ku-ten notation: %s", s)
+	}
+	if _, err := Jis2Uni(men, ku, ten); err != nil {
+		return JisEntry{0, 0, 0}, errors.WithStack(err)
+	}
+	j := JisEntry{Men: int8(men), Ku: int8(ku), Ten: int8(ten)}
+	if level != 0 && level != j.Level() {
+		return JisEntry{0, 0, 0}, errors.Errorf("level mismatch: 第%d水準 but %s is 第%d水準", level, j.String(), j.Level())
+	}
+	return j, nil
+}
@@ -0,0 +1,118 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJisEntryString(t *testing.T) {
+	var convertedPairs = []struct {
+		in       JisEntry
+		str      string
+		level    int
+		notation string
+	}{
+		{JisEntry{Men: 1, Ku: 4, Ten: 2}, "1-4-2", 1, "第1水準1-4-2"},
+		{JisEntry{Men: 1, Ku: 16, Ten: 1}, "1-16-1", 1, "第1水準1-16-1"},
+		{JisEntry{Men: 1, Ku: 48, Ten: 1}, "1-48-1", 2, "第2水準1-48-1"},
+		{JisEntry{Men: 1, Ku: 85, Ten: 9}, "1-85-9", 3, "第3水準1-85-9"},
+		{JisEntry{Men: 1, Ku: 47, Ten: 52}, "1-47-52", 3, "第3水準1-47-52"},
+		{JisEntry{Men: 2, Ku: 1, Ten: 1}, "2-1-1", 4, "第4水準2-1-1"},
+	}
+	for _, tt := range convertedPairs {
+		if got := tt.in.String(); got != tt.str {
+			t.Errorf("String got: %v want: %v", got, tt.str)
+		}
+		if got := tt.in.Level(); got != tt.level {
+			t.Errorf("Level %v got: %v want: %v", tt.in, got, tt.level)
+		}
+		if got := tt.in.AozoraNotation(); got != tt.notation {
+			t.Errorf("AozoraNotation got: %v want: %v", got, tt.notation)
+		}
+	}
+}
+
+func TestJisEntryBytes(t *testing.T) {
+	var convertedPairs = []struct {
+		in        JisEntry
+		sjis      []byte
+		euc       []byte
+		isSuccess bool
+	}{
+		{JisEntry{Men: 1, Ku: 4, Ten: 2}, []byte{0x82, 0xA0}, []byte{0xA4, 0xA2}, true},
+		{JisEntry{Men: 1, Ku: 47, Ten: 52}, []byte{0x98, 0x73}, []byte{0xCF, 0xD4}, true},
+		{JisEntry{Men: 2, Ku: 1, Ten: 1}, []byte{0xF0, 0x40}, []byte{0x8F, 0xA1, 0xA1}, true},
+		{JisEntry{0, 0, 0}, nil, nil, false},
+	}
+	for _, tt := range convertedPairs {
+		sjis, err := tt.in.ShiftJIS2004Bytes()
+		if (err == nil) != tt.isSuccess {
+			t.Errorf("ShiftJIS2004Bytes %v: unexpected error state %v", tt.in, err)
+		}
+		if bytes.Equal(sjis, tt.sjis) != true {
+			t.Errorf("ShiftJIS2004Bytes %v got: %X want: %X", tt.in, sjis, tt.sjis)
+		}
+		euc, err := tt.in.EUCBytes()
+		if (err == nil) != tt.isSuccess {
+			t.Errorf("EUCBytes %v: unexpected error state %v", tt.in, err)
+		}
+		if bytes.Equal(euc, tt.euc) != true {
+			t.Errorf("EUCBytes %v got: %X want: %X", tt.in, euc, tt.euc)
+		}
+	}
+}
+
+func TestJisEntryUnicode(t *testing.T) {
+	for _, s := range []string{"あ", "。", "亜", "𠂉", "か゚"} {
+		j, err := Uni2Jis(s)
+		if err != nil {
+			t.Fatalf("Uni2Jis %v: %+v", s, err)
+		}
+		got, err := j.Unicode()
+		if err != nil {
+			t.Errorf("Unicode %v: %+v", j, err)
+		}
+		if got != s {
+			t.Errorf("Unicode %v got: %v want: %v", j, got, s)
+		}
+	}
+	if _, err := (JisEntry{0, 0, 0}).Unicode(); err == nil {
+		t.Errorf("zero entry should be error")
+	}
+}
+
+func TestParseMenKuTen(t *testing.T) {
+	var convertedPairs = []struct {
+		in        string
+		out       JisEntry
+		isSuccess bool
+	}{
+		{"1-85-9", JisEntry{Men: 1, Ku: 85, Ten: 9}, true},
+		{"第3水準1-85-9", JisEntry{Men: 1, Ku: 85, Ten: 9}, true},
+		{"第4水準2-1-1", JisEntry{Men: 2, Ku: 1, Ten: 1}, true},
+		{"1-4-2", JisEntry{Men: 1, Ku: 4, Ten: 2}, true},
+		{"第4水準1-85-9", JisEntry{0, 0, 0}, false},
+		{"2-2-80", JisEntry{0, 0, 0}, false},
+		{"1-85", JisEntry{0, 0, 0}, false},
+		{"1-85-9x", JisEntry{0, 0, 0}, false},
+		{"U+3042", JisEntry{0, 0, 0}, false},
+	}
+	for _, tt := range convertedPairs {
+		got, err := ParseMenKuTen(tt.in)
+		if err != nil && tt.isSuccess {
+			t.Errorf("ParseMenKuTen %v; should not be error but %v", tt.in, err)
+		} else if err == nil && !tt.isSuccess {
+			t.Errorf("ParseMenKuTen %v; should be error but got %v", tt.in, got)
+		}
+		if got != tt.out {
+			t.Errorf("ParseMenKuTen %v got: %v want: %v", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestJisEntryGaijiAnnotation(t *testing.T) {
+	j := JisEntry{Men: 1, Ku: 92, Ten: 36}
+	if got, want := j.GaijiAnnotation("足へん＋宛"), "※［＃「足へん＋宛」、第3水準1-92-36］"; got != want {
+		t.Errorf("GaijiAnnotation got: %v want: %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package aozoraconv
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	okuriganaAnnotation = regexp.MustCompile(`^（([ぁ-ゖァ-ヺー]+)）$`)
+)
+
+// kaeritenOrder is series and rank of kaeriten, a character with rank n+1
+// is read after the character with rank n in the same series
+var kaeritenOrder = map[string][2]int{
+	"一": {0, 1}, "二": {0, 2}, "三": {0, 3}, "四": {0, 4},
+	"上": {1, 1}, "中": {1, 2}, "下": {1, 3},
+	"甲": {2, 1}, "乙": {2, 2}, "丙": {2, 3}, "丁": {2, 4},
+	"天": {3, 1}, "地": {3, 2}, "人": {3, 3},
+}
+
+const kaeritenRe = "レ"
+
+func isKaeriten(s string) bool {
+	if s == kaeritenRe {
+		return true
+	}
+	s = strings.TrimSuffix(s, kaeritenRe)
+	_, ok := kaeritenOrder[s]
+	return ok
+}
+
+func newKanbun(body string) (*Kanbun, bool) {
+	if isKaeriten(body) {
+		return &Kanbun{Kaeriten: body}, true
+	}
+	if m := okuriganaAnnotation.FindStringSubmatch(body); m != nil {
+		return &Kanbun{Okurigana: m[1]}, true
+	}
+	return nil, false
+}
+
+type kanbunUnit struct {
+	text      string
+	okurigana string
+	re        bool
+	series    int
+	rank      int
+	punct     bool
+}
+
+// kanbunUnits splits nodes into characters with okurigana and kaeriten
+func kanbunUnits(nodes []Node) []*kanbunUnit {
+	units := make([]*kanbunUnit, 0, len(nodes))
+	last := func() *kanbunUnit {
+		if len(units) < 1 {
+			u := new(kanbunUnit)
+			units = append(units, u)
+			return u
+		}
+		return units[len(units)-1]
+	}
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			for _, r := range v.Value {
+				units = append(units, &kanbunUnit{text: string(r), punct: isKanbunPunct(r)})
+			}
+		case *Ruby, *Gaiji:
+			units = append(units, &kanbunUnit{text: PlainText([]Node{n})})
+		case *Layout:
+			units = append(units, kanbunUnits(v.Nodes)...)
+		case *Kanbun:
+			u := last()
+			if v.Okurigana != "" {
+				u.okurigana += v.Okurigana
+			}
+			if v.Kaeriten != "" {
+				mark := v.Kaeriten
+				if strings.HasSuffix(mark, kaeritenRe) {
+					u.re = true
+					mark = strings.TrimSuffix(mark, kaeritenRe)
+				}
+				if order, ok := kaeritenOrder[mark]; ok {
+					u.series, u.rank = order[0], order[1]
+				}
+			}
+		}
+	}
+	return units
+}
+
+func isKanbunPunct(r rune) bool {
+	return strings.ContainsRune("。、，．・「」『』（）〔〕　 ！？", r)
+}
+
+// Kakikudashi returns text of nodes in the reading order of kaeriten (書き下し順),
+// okurigana is converted into hiragana
+func Kakikudashi(nodes []Node) string {
+	units := kanbunUnits(nodes)
+	emitted := make([]bool, len(units))
+	buf := new(strings.Builder)
+
+	var emit func(int)
+	emit = func(i int) {
+		if emitted[i] {
+			return
+		}
+		emitted[i] = true
+		u := units[i]
+		buf.WriteString(u.text)
+		buf.WriteString(katakanaToHiragana(u.okurigana))
+
+		// レ: previous character is read just after this character
+		if 0 < i && units[i-1].re {
+			emit(i - 1)
+		}
+		// 一二, 上中下...: read next rank in the same series
+		if 0 < u.rank {
+			next, nextRank := -1, 0
+			for k := i - 1; 0 <= k; k -= 1 {
+				p := units[k]
+				if emitted[k] || p.series != u.series || p.rank <= u.rank {
+					continue
+				}
+				if next < 0 || p.rank < nextRank {
+					next, nextRank = k, p.rank
+				}
+			}
+			if 0 <= next {
+				emit(next)
+			}
+		}
+	}
+	flush := func(end int) {
+		for k := 0; k < end; k += 1 {
+			emit(k)
+		}
+	}
+
+	for i, u := range units {
+		switch {
+		case u.punct:
+			flush(i)
+			emit(i)
+		case u.re:
+			continue
+		case 1 < u.rank:
+			continue
+		default:
+			emit(i)
+		}
+	}
+	flush(len(units))
+	return buf.String()
+}
+
+func katakanaToHiragana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'ァ' <= r && r <= 'ヶ' {
+			return r - ('ァ' - 'ぁ')
+		}
+		return r
+	}, s)
+}
+
+type kanbunEscaper struct{}
+
+func (e *kanbunEscaper) Escape(src string) (string, bool) {
+	line := ParseLine(src)
+	found := false
+	for _, n := range line.Nodes {
+		if _, ok := n.(*Kanbun); ok {
+			found = true
+			break
+		}
+	}
+	if found != true {
+		return src, true
+	}
+	return Kakikudashi(line.Nodes) + line.EOL, true
+}
+
+func newKanbunEscaper() *kanbunEscaper {
+	return &kanbunEscaper{}
+}
+
+// NewKanbunEscaper returns Escaper that reorders lines with kaeriten into the reading order (書き下し)
+func NewKanbunEscaper() Escaper {
+	return newKanbunEscaper()
+}
@@ -0,0 +1,95 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKanbun(t *testing.T) {
+	actual := ParseLine("不［＃（ル）］［＃レ］知［＃一レ］［＃（ｘ）］")
+	expect := []Node{
+		&Text{"不"},
+		&Kanbun{Okurigana: "ル"},
+		&Kanbun{Kaeriten: "レ"},
+		&Text{"知"},
+		&Kanbun{Kaeriten: "一レ"},
+		&Annotation{Body: "（ｘ）"},
+	}
+	if reflect.DeepEqual(expect, actual.Nodes) != true {
+		t.Errorf("expect=%s actual=%s", dumpNodes(expect), dumpNodes(actual.Nodes))
+	}
+}
+
+func TestKakikudashi(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{
+			in:     "学［＃（ビテ）］而時［＃（ニ）］習［＃（フ）］［＃レ］之［＃（ヲ）］",
+			expect: "学びて而時に之を習ふ",
+		},
+		{
+			in:     "読［＃二］書［＃一］",
+			expect: "書読",
+		},
+		{
+			in:     "有［＃下］能［＃（ク）］治［＃（ムル）］［＃二］国［＃（ヲ）］［＃一］者［＃上］",
+			expect: "能く国を治むる者有",
+		},
+		{
+			in:     "Ａ［＃二］Ｂ［＃レ］Ｃ［＃一］",
+			expect: "ＣＢＡ",
+		},
+		{
+			in:     "Ａ［＃レ］Ｂ［＃二］Ｃ［＃一］",
+			expect: "ＣＢＡ",
+		},
+		{
+			in:     "Ａ［＃レ］Ｂ［＃レ］Ｃ",
+			expect: "ＣＢＡ",
+		},
+		{
+			in:     "不［＃レ］知。不［＃二］可［＃一レ］言",
+			expect: "知不。言可不",
+		},
+		{
+			in:     "読［＃二］書",
+			expect: "書読",
+		},
+	}
+	for _, tc := range tests {
+		actual := Kakikudashi(ParseLine(tc.in).Nodes)
+		if actual != tc.expect {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, tc.expect, actual)
+		}
+	}
+}
+
+func TestConvKanbunReorder(t *testing.T) {
+	in := "子曰［＃（ク）］\r\n学［＃（ビテ）］而時［＃（ニ）］習［＃（フ）］［＃レ］之［＃（ヲ）］\r\n"
+	out := bytes.NewBuffer(nil)
+	if err := Conv(out, strings.NewReader(in), WithKanbunReorder()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if out.String() != "子曰く\r\n学びて而時に之を習ふ\r\n" {
+		t.Errorf("actual=%s", out.String())
+	}
+}
+
+func TestRenderHTMLKanbun(t *testing.T) {
+	doc, err := Parse(strings.NewReader("不［＃（ル）］［＃レ］知"))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := RenderHTML(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := `不<sup class="okurigana">ル</sup><sub class="kaeriten">レ</sub>知`
+	if strings.Contains(out.String(), expect) != true {
+		t.Errorf("expect contains %s\nactual=%s", expect, out.String())
+	}
+}
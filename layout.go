@@ -0,0 +1,131 @@
+package aozoraconv
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	LayoutTatechuyoko = "縦中横"
+	LayoutWarichu     = "割り注"
+	LayoutYokogumi    = "横組み"
+	LayoutKeigakomi   = "罫囲み"
+	LayoutSuperscript = "上付き小文字"
+	LayoutSubscript   = "下付き小文字"
+	LayoutKogaki      = "小書き"
+)
+
+var (
+	layoutKinds     = `(縦中横|割り注|横組み|罫囲み|上付き小文字|下付き小文字|小書き)`
+	layoutReference = regexp.MustCompile(`^「(.+)」は` + layoutKinds + `$`)
+	layoutStart     = regexp.MustCompile(`^(ここから)?` + layoutKinds + `$`)
+	layoutEnd       = regexp.MustCompile(`^(ここで)?` + layoutKinds + `終わり$`)
+)
+
+var (
+	_ Node = (*Layout)(nil)
+	_ Node = (*LayoutStart)(nil)
+	_ Node = (*LayoutEnd)(nil)
+)
+
+// Layout is an inline layout applied to nodes, annotated by reference
+// (10［＃「10」は縦中横］) or by range (［＃割り注］…［＃割り注終わり］) in a line
+type Layout struct {
+	Kind      string
+	Nodes     []Node
+	Reference bool
+}
+
+// LayoutStart is the start of a layout range which does not end in the line
+// (［＃ここから横組み］ is Block)
+type LayoutStart struct {
+	Kind  string
+	Block bool
+}
+
+// LayoutEnd is the end of a layout range which does not start in the line
+// (［＃ここで横組み終わり］ is Block)
+type LayoutEnd struct {
+	Kind  string
+	Block bool
+}
+
+func (*Layout) aozoraNode()      {}
+func (*LayoutStart) aozoraNode() {}
+func (*LayoutEnd) aozoraNode()   {}
+
+// layout handles layout annotations, returns false if body is not a layout annotation
+func (p *lineParser) layout(body string) bool {
+	if m := layoutReference.FindStringSubmatch(body); m != nil {
+		if 0 <= p.rubyStart {
+			return false
+		}
+		p.flush()
+		rest, target, ok := splitTrailing(p.nodes, m[1])
+		if ok != true {
+			return false
+		}
+		p.nodes = append(rest, &Layout{Kind: m[2], Nodes: target, Reference: true})
+		return true
+	}
+	if m := layoutStart.FindStringSubmatch(body); m != nil {
+		p.push(&LayoutStart{Kind: m[2], Block: m[1] != ""})
+		return true
+	}
+	if m := layoutEnd.FindStringSubmatch(body); m != nil {
+		p.flush()
+		block := m[1] != ""
+		for i := len(p.nodes) - 1; 0 <= i; i -= 1 {
+			s, ok := p.nodes[i].(*LayoutStart)
+			if ok != true || s.Kind != m[2] || s.Block != block {
+				continue
+			}
+			if block || (0 <= p.rubyStart && i < p.rubyStart) {
+				break
+			}
+			children := mergeText(append([]Node(nil), p.nodes[i+1:]...))
+			p.nodes = append(p.nodes[:i], &Layout{Kind: m[2], Nodes: children})
+			return true
+		}
+		p.push(&LayoutEnd{Kind: m[2], Block: block})
+		return true
+	}
+	return false
+}
+
+// splitTrailing splits nodes into preceding nodes and trailing nodes of
+// which text is target, text node is split if target starts in the middle of it
+func splitTrailing(nodes []Node, target string) ([]Node, []Node, bool) {
+	matched := make([]Node, 0, 2)
+	rest := target
+	for i := len(nodes) - 1; 0 <= i; i -= 1 {
+		if t, ok := nodes[i].(*Text); ok {
+			switch {
+			case strings.HasSuffix(t.Value, rest):
+				head := t.Value[:len(t.Value)-len(rest)]
+				matched = append([]Node{&Text{Value: rest}}, matched...)
+				preceding := append([]Node(nil), nodes[:i]...)
+				if head != "" {
+					preceding = append(preceding, &Text{Value: head})
+				}
+				return preceding, matched, true
+			case strings.HasSuffix(rest, t.Value):
+				rest = rest[:len(rest)-len(t.Value)]
+				matched = append([]Node{t}, matched...)
+				continue
+			}
+			return nil, nil, false
+		}
+
+		text := PlainText([]Node{nodes[i]})
+		if text == "" || strings.HasSuffix(rest, text) != true {
+			return nil, nil, false
+		}
+		rest = rest[:len(rest)-len(text)]
+		matched = append([]Node{nodes[i]}, matched...)
+		if rest == "" {
+			return append([]Node(nil), nodes[:i]...), matched, true
+		}
+	}
+	return nil, nil, false
+}
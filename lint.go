@@ -0,0 +1,336 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+const (
+	LintReadError            = "read-error"
+	LintUnbalancedRuby       = "unbalanced-ruby"
+	LintUnbalancedAnnotation = "unbalanced-annotation"
+	LintUnclosedBlock        = "unclosed-block"
+	LintUnmatchedBlockEnd    = "unmatched-block-end"
+	LintReferenceNotFound    = "reference-not-found"
+	LintUnknownAnnotation    = "unknown-annotation"
+	LintHalfWidth            = "half-width"
+	LintNonJIS               = "non-jis"
+	LintMissingHeader        = "missing-header"
+	LintMissingFooter        = "missing-footer"
+)
+
+// LintRule is a rule checked by Lint
+type LintRule struct {
+	ID          string
+	Severity    Severity
+	Description string
+}
+
+var lintRules = []LintRule{
+	{LintReadError, SeverityError, "text can not be read"},
+	{LintUnbalancedRuby, SeverityError, "《 and 》 must be balanced in a line"},
+	{LintUnbalancedAnnotation, SeverityError, "［＃ must be closed by ］ in a line"},
+	{LintUnclosedBlock, SeverityError, "ここから… must be closed by ここで…終わり"},
+	{LintUnmatchedBlockEnd, SeverityError, "ここで…終わり must follow ここから…"},
+	{LintReferenceNotFound, SeverityError, "target of 「…」は… annotation must precede it in the line"},
+	{LintUnknownAnnotation, SeverityWarning, "annotation keyword is not defined in the notation guidelines"},
+	{LintHalfWidth, SeverityWarning, "body text should use full-width characters"},
+	{LintNonJIS, SeverityError, "character not in JIS X 0208 must be written in gaiji notation"},
+	{LintMissingHeader, SeverityWarning, "text should start with title, author and notation explanation"},
+	{LintMissingFooter, SeverityWarning, "text should end with bibliographic information (底本：)"},
+}
+
+// LintRules returns rules checked by Lint
+func LintRules() []LintRule {
+	return append([]LintRule(nil), lintRules...)
+}
+
+func lintSeverity(rule string) Severity {
+	for _, r := range lintRules {
+		if r.ID == rule {
+			return r.Severity
+		}
+	}
+	return SeverityError
+}
+
+// Diagnostic is a problem found by Lint, Line and Col are 1-based (Col counts characters)
+type Diagnostic struct {
+	Line     int      `json:"line"`
+	Col      int      `json:"col"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.Line, d.Col, d.Severity, d.Message, d.Rule)
+}
+
+var (
+	blockStart          = regexp.MustCompile(`^ここから(.+)$`)
+	blockEnd            = regexp.MustCompile(`^ここで(.+)終わり$`)
+	referenceAnnotation = regexp.MustCompile(`^「([^」]+)」(?:は|に|の左に)`)
+	blockKindNumber     = regexp.MustCompile(`[0-9０-９一二三四五六七八九十]+`)
+	knownAnnotation     = regexp.MustCompile(`字下げ|字上げ|地付き|地寄せ|字詰め|天付き|見出し|傍点|傍線|波線|破線|鎖線|白丸|丸傍|ゴマ|三角|蛇の目|ばつ|太字|斜体|ルビ|注記|底本|ママ|大きな文字|小さな文字|縦中横|横組み|割り注|罫囲み|小書き|上付き|下付き|返り点|訓点|入力者注|ページの左右中央|本文終わり|訂正|削除|入る`)
+)
+
+// blockKind returns kind of block annotation that is used to match
+// ここから２字下げ with ここで字下げ終わり
+func blockKind(s string) string {
+	for _, k := range []string{"字下げ", "字上げ", "字詰め", "小さな文字", "大きな文字"} {
+		if strings.Contains(s, k) {
+			return k
+		}
+	}
+	return blockKindNumber.ReplaceAllString(s, "")
+}
+
+func isKnownAnnotation(body string) bool {
+	if _, ok := newAnnotationNode(body).(*Annotation); ok != true {
+		return true
+	}
+	if layoutReference.MatchString(body) || layoutStart.MatchString(body) || layoutEnd.MatchString(body) {
+		return true
+	}
+	return knownAnnotation.MatchString(body)
+}
+
+func isHalfWidth(r rune) bool {
+	return (0x20 <= r && r < 0x7f) || (0xff61 <= r && r <= 0xff9f)
+}
+
+// isJIS0208 reports whether r can be written in Shift_JIS without gaiji notation
+func isJIS0208(r rune) bool {
+	if r < 0x80 {
+		return true
+	}
+	for _, s := range []string{string(r), aozoraUtf8CharReplacer.Replace(string(r))} {
+		b, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(s))
+		if err != nil || len(b) != 2 {
+			continue
+		}
+		if men, ku, ten, err := Sjis2Kuten(b); err == nil && Is0208(men, ku, ten) {
+			return true
+		}
+	}
+	return false
+}
+
+type lintBlock struct {
+	kind      string
+	line, col int
+}
+
+type linter struct {
+	diags    []Diagnostic
+	blocks   []lintBlock
+	nonJIS   map[rune]bool
+	yokogumi bool // in ここから横組み block
+}
+
+func (l *linter) report(line, col int, rule, format string, args ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{
+		Line:     line,
+		Col:      col,
+		Severity: lintSeverity(rule),
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) isNonJIS(r rune) bool {
+	v, ok := l.nonJIS[r]
+	if ok != true {
+		v = isJIS0208(r) != true
+		l.nonJIS[r] = v
+	}
+	return v
+}
+
+// lintLine checks a line (without EOL), notations are checked only in body
+func (l *linter) lintLine(lineNo int, src string, body bool) {
+	// targets of 縦中横 and 横組み may be half-width
+	exempt := widthExemptRanges(src)
+	inRange := 0
+	rubyOpen := 0
+	halfStart := 0
+	col := 1
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		open := ""
+		switch {
+		case strings.HasPrefix(rest, notationGaijiOpen):
+			open = notationGaijiOpen
+		case strings.HasPrefix(rest, notationAnnotationOpen):
+			open = notationAnnotationOpen
+		}
+		if open != "" {
+			annotation, n, ok := annotationBody(rest[len(open):])
+			if ok {
+				if 0 < halfStart {
+					l.report(lineNo, halfStart, LintHalfWidth, "half-width characters in body text")
+					halfStart = 0
+				}
+				if body && open == notationAnnotationOpen {
+					l.lintAnnotation(lineNo, col, annotation, src[:i])
+					inRange += inlineWidthRange(annotation)
+				}
+				size := len(open) + n
+				col += utf8.RuneCountInString(src[i : i+size])
+				i += size
+				continue
+			}
+			if body {
+				l.report(lineNo, col, LintUnbalancedAnnotation, "［＃ is not closed")
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if body {
+			switch r {
+			case '《':
+				if 0 < rubyOpen {
+					l.report(lineNo, rubyOpen, LintUnbalancedRuby, "《 is not closed")
+				}
+				rubyOpen = col
+			case '》':
+				if rubyOpen < 1 {
+					l.report(lineNo, col, LintUnbalancedRuby, "》 without 《")
+				}
+				rubyOpen = 0
+			}
+		}
+		switch {
+		case body && l.yokogumi != true && inRange < 1 && exempt(i) != true && isHalfWidth(r):
+			if halfStart < 1 {
+				halfStart = col
+			}
+		case 0 < halfStart:
+			l.report(lineNo, halfStart, LintHalfWidth, "half-width characters in body text")
+			halfStart = 0
+		}
+		if l.isNonJIS(r) {
+			l.report(lineNo, col, LintNonJIS, "%c (U+%04X) is not in JIS X 0208, use gaiji notation", r, r)
+		}
+		col += 1
+		i += size
+	}
+	if 0 < halfStart {
+		l.report(lineNo, halfStart, LintHalfWidth, "half-width characters in body text")
+	}
+	if 0 < rubyOpen {
+		l.report(lineNo, rubyOpen, LintUnbalancedRuby, "《 is not closed")
+	}
+}
+
+// inlineWidthRange returns 1 for start and -1 for end of inline 縦中横 or 横組み range
+func inlineWidthRange(body string) int {
+	if m := layoutStart.FindStringSubmatch(body); m != nil && m[1] == "" && (m[2] == LayoutTatechuyoko || m[2] == LayoutYokogumi) {
+		return 1
+	}
+	if m := layoutEnd.FindStringSubmatch(body); m != nil && m[1] == "" && (m[2] == LayoutTatechuyoko || m[2] == LayoutYokogumi) {
+		return -1
+	}
+	return 0
+}
+
+func (l *linter) lintAnnotation(lineNo, col int, body, preceding string) {
+	if m := blockStart.FindStringSubmatch(body); m != nil {
+		kind := blockKind(m[1])
+		l.blocks = append(l.blocks, lintBlock{kind: kind, line: lineNo, col: col})
+		if kind == LayoutYokogumi {
+			l.yokogumi = true
+		}
+	}
+	if m := blockEnd.FindStringSubmatch(body); m != nil {
+		kind := blockKind(m[1])
+		found := false
+		for i := len(l.blocks) - 1; 0 <= i; i -= 1 {
+			if l.blocks[i].kind == kind {
+				l.blocks = append(l.blocks[:i], l.blocks[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if found != true {
+			l.report(lineNo, col, LintUnmatchedBlockEnd, "［＃%s］ without ここから%s", body, kind)
+		}
+		if kind == LayoutYokogumi {
+			l.yokogumi = false
+		}
+	}
+	if m := referenceAnnotation.FindStringSubmatch(body); m != nil {
+		target := m[1]
+		if strings.Contains(PlainText(ParseLine(preceding).Nodes), target) != true && strings.Contains(preceding, target) != true {
+			l.report(lineNo, col, LintReferenceNotFound, "「%s」 does not occur before annotation", target)
+		}
+	}
+	if isKnownAnnotation(body) != true {
+		l.report(lineNo, col, LintUnknownAnnotation, "unknown annotation ［＃%s］", body)
+	}
+}
+
+// Lint checks Aozora Bunko format text (UTF-8, CRLF) against the input guidelines
+func Lint(r io.Reader) []Diagnostic {
+	l := &linter{
+		diags:  make([]Diagnostic, 0, 16),
+		blocks: make([]lintBlock, 0, 4),
+		nonJIS: make(map[rune]bool, 256),
+	}
+
+	lines := make([]string, 0, 1024)
+	scan := NewAozoraTextScanner(r)
+	for scan.Scan() {
+		lines = append(lines, strings.TrimSuffix(scan.Text(), "\r\n"))
+	}
+	if err := scan.Err(); err != nil {
+		l.report(len(lines)+1, 1, LintReadError, "%v", err)
+		return l.diags
+	}
+
+	doc := &Document{Lines: make([]*Line, 0, len(lines))}
+	for _, s := range lines {
+		doc.Lines = append(doc.Lines, ParseLine(s))
+	}
+	header, body, footer := doc.Split()
+	bodyStart, bodyEnd := len(header), len(header)+len(body)
+	if len(header) < 1 {
+		l.report(1, 1, LintMissingHeader, "header (title, author and notation explanation) is missing")
+	}
+
+	for i, s := range lines {
+		l.lintLine(i+1, s, bodyStart <= i && i < bodyEnd)
+	}
+	for _, b := range l.blocks {
+		l.report(b.line, b.col, LintUnclosedBlock, "ここから%s is not closed", b.kind)
+	}
+
+	if len(footer) < 1 {
+		last := len(lines)
+		if last < 1 {
+			last = 1
+		}
+		l.report(last, 1, LintMissingFooter, "footer (底本：) is missing")
+	}
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].Line != l.diags[j].Line {
+			return l.diags[i].Line < l.diags[j].Line
+		}
+		return l.diags[i].Col < l.diags[j].Col
+	})
+	return l.diags
+}
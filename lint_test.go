@@ -0,0 +1,99 @@
+package aozoraconv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"停車場《ステーション",
+		"［＃ここから２字下げ］",
+		"昭和10［＃「10」は縦中横］年の話",
+		"［＃ここで字下げ終わり］",
+		"駅前［＃「停車場」に傍点］［＃謎の指定］",
+		"ABC［＃閉じていない",
+		"𠀋※［＃「てへん＋劣」、206-4］",
+		"［＃ここから太字］",
+		"［＃ここで罫囲み終わり］",
+		"",
+		"底本：「真山青果全集」",
+		"",
+	}, "\r\n")
+	diags := Lint(strings.NewReader(in))
+
+	expects := []Diagnostic{
+		{9, 4, SeverityError, LintUnbalancedRuby, ""},
+		{13, 3, SeverityError, LintReferenceNotFound, ""},
+		{13, 14, SeverityWarning, LintUnknownAnnotation, ""},
+		{14, 1, SeverityWarning, LintHalfWidth, ""},
+		{14, 4, SeverityError, LintUnbalancedAnnotation, ""},
+		{15, 1, SeverityError, LintNonJIS, ""},
+		{16, 1, SeverityError, LintUnclosedBlock, ""},
+		{17, 1, SeverityError, LintUnmatchedBlockEnd, ""},
+	}
+	if len(diags) != len(expects) {
+		t.Fatalf("expect %d diagnostics, actual=%v", len(expects), diags)
+	}
+	for i, expect := range expects {
+		d := diags[i]
+		if d.Line != expect.Line || d.Col != expect.Col || d.Severity != expect.Severity || d.Rule != expect.Rule {
+			t.Errorf("[%d] expect=%v actual=%v", i, expect, d)
+		}
+	}
+}
+
+func TestLintMissingHeaderFooter(t *testing.T) {
+	diags := Lint(strings.NewReader("本文\r\n"))
+	rules := make([]string, 0, len(diags))
+	for _, d := range diags {
+		rules = append(rules, d.Rule)
+	}
+	if strings.Join(rules, ",") != LintMissingHeader+","+LintMissingFooter {
+		t.Errorf("actual=%v", diags)
+	}
+}
+
+func TestLintHalfWidthExempt(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"abc10［＃「10」は縦中横］年",
+		"あ［＃縦中横］12［＃縦中横終わり］ab",
+		"［＃ここから横組み］",
+		"H2O",
+		"［＃ここで横組み終わり］",
+		"",
+		"底本：「真山青果全集」",
+		"",
+	}, "\r\n")
+	expects := [][2]int{{9, 1}, {10, 19}}
+	actual := make([][2]int, 0, len(expects))
+	for _, d := range Lint(strings.NewReader(in)) {
+		if d.Rule == LintHalfWidth {
+			actual = append(actual, [2]int{d.Line, d.Col})
+		}
+	}
+	if len(actual) != len(expects) {
+		t.Fatalf("expect=%v actual=%v", expects, actual)
+	}
+	for i, expect := range expects {
+		if actual[i] != expect {
+			t.Errorf("[%d] expect=%v actual=%v", i, expect, actual[i])
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// kyujitaiTable maps 旧字体 into 新字体 (常用漢字表)
+var kyujitaiTable = map[rune]rune{
+	'亞': '亜', '惡': '悪', '壓': '圧', '圍': '囲', '醫': '医', '爲': '為', '壹': '壱', '隱': '隠',
+	'榮': '栄', '營': '営', '衞': '衛', '驛': '駅', '圓': '円', '鹽': '塩', '緣': '縁', '艷': '艶',
+	'應': '応', '歐': '欧', '毆': '殴', '櫻': '桜', '奧': '奥', '價': '価', '假': '仮', '會': '会',
+	'壞': '壊', '懷': '懐', '繪': '絵', '擴': '拡', '殼': '殻', '覺': '覚', '學': '学', '嶽': '岳',
+	'樂': '楽', '渴': '渇', '卷': '巻', '陷': '陥', '勸': '勧', '寬': '寛', '關': '関', '歡': '歓',
+	'觀': '観', '氣': '気', '歸': '帰', '龜': '亀', '僞': '偽', '戲': '戯', '犧': '犠', '舊': '旧',
+	'據': '拠', '擧': '挙', '峽': '峡', '挾': '挟', '狹': '狭', '曉': '暁', '區': '区', '驅': '駆',
+	'勳': '勲', '徑': '径', '惠': '恵', '揭': '掲', '溪': '渓', '經': '経', '莖': '茎', '螢': '蛍',
+	'輕': '軽', '鷄': '鶏', '藝': '芸', '缺': '欠', '儉': '倹', '劍': '剣', '圈': '圏', '檢': '検',
+	'權': '権', '獻': '献', '縣': '県', '險': '険', '顯': '顕', '驗': '験', '嚴': '厳', '效': '効',
+	'廣': '広', '恆': '恒', '鑛': '鉱', '號': '号', '國': '国', '濟': '済', '碎': '砕', '齋': '斎',
+	'劑': '剤', '雜': '雑', '參': '参', '慘': '惨', '棧': '桟', '蠶': '蚕', '贊': '賛', '殘': '残',
+	'絲': '糸', '齒': '歯', '兒': '児', '辭': '辞', '濕': '湿', '實': '実', '舍': '舎', '寫': '写',
+	'釋': '釈', '壽': '寿', '收': '収', '從': '従', '澁': '渋', '獸': '獣', '縱': '縦', '肅': '粛',
+	'處': '処', '敍': '叙', '奬': '奨', '將': '将', '燒': '焼', '稱': '称', '證': '証', '乘': '乗',
+	'剩': '剰', '壤': '壌', '孃': '嬢', '條': '条', '淨': '浄', '疊': '畳', '讓': '譲', '釀': '醸',
+	'觸': '触', '囑': '嘱', '眞': '真', '寢': '寝', '愼': '慎', '盡': '尽', '粹': '粋', '醉': '酔',
+	'隨': '随', '髓': '髄', '數': '数', '樞': '枢', '聲': '声', '靜': '静', '齊': '斉', '攝': '摂',
+	'竊': '窃', '專': '専', '戰': '戦', '淺': '浅', '潛': '潜', '纖': '繊', '踐': '践', '錢': '銭',
+	'禪': '禅', '雙': '双', '壯': '壮', '搜': '捜', '插': '挿', '爭': '争', '總': '総', '聰': '聡',
+	'莊': '荘', '裝': '装', '騷': '騒', '藏': '蔵', '臟': '臓', '屬': '属', '續': '続', '墮': '堕',
+	'體': '体', '對': '対', '帶': '帯', '滯': '滞', '臺': '台', '瀧': '滝', '擇': '択', '澤': '沢',
+	'單': '単', '擔': '担', '膽': '胆', '團': '団', '彈': '弾', '斷': '断', '癡': '痴', '遲': '遅',
+	'晝': '昼', '蟲': '虫', '鑄': '鋳', '廳': '庁', '聽': '聴', '敕': '勅', '鎭': '鎮', '遞': '逓',
+	'鐵': '鉄', '轉': '転', '點': '点', '傳': '伝', '黨': '党', '盜': '盗', '燈': '灯', '當': '当',
+	'鬪': '闘', '德': '徳', '獨': '独', '讀': '読', '屆': '届', '繩': '縄', '貳': '弐', '惱': '悩',
+	'腦': '脳', '霸': '覇', '廢': '廃', '拜': '拝', '賣': '売', '麥': '麦', '發': '発', '髮': '髪',
+	'拔': '抜', '蠻': '蛮', '祕': '秘', '濱': '浜', '甁': '瓶', '拂': '払', '佛': '仏', '竝': '並',
+	'變': '変', '邊': '辺', '辯': '弁', '瓣': '弁', '辨': '弁', '步': '歩', '寶': '宝', '豐': '豊',
+	'沒': '没', '飜': '翻', '每': '毎', '萬': '万', '滿': '満', '默': '黙', '彌': '弥', '譯': '訳',
+	'藥': '薬', '與': '与', '豫': '予', '餘': '余', '譽': '誉', '搖': '揺', '樣': '様', '謠': '謡',
+	'來': '来', '賴': '頼', '亂': '乱', '覽': '覧', '龍': '竜', '兩': '両', '獵': '猟', '綠': '緑',
+	'壘': '塁', '淚': '涙', '勵': '励', '禮': '礼', '隸': '隷', '靈': '霊', '齡': '齢', '戀': '恋',
+	'爐': '炉', '勞': '労', '樓': '楼', '祿': '禄', '錄': '録', '灣': '湾', '黑': '黒', '姊': '姉',
+	'卽': '即', '曾': '曽', '瘦': '痩', '黃': '黄', '稻': '稲', '徵': '徴', '麵': '麺', '戶': '戸',
+	'淸': '清', '靑': '青', '郞': '郎', '圖': '図', '狀': '状', '巢': '巣', '兔': '兎', '册': '冊',
+	'薰': '薫', '顏': '顔', '穗': '穂', '舖': '舗', '晚': '晩',
+}
+
+// kanaWords maps words in historical kana orthography (歴史的仮名遣い) into modern kana
+var kanaWords = map[string]string{
+	"てふてふ":   "ちょうちょう",
+	"けふ":     "きょう",
+	"いふ":     "いう",
+	"やうに":    "ように",
+	"やうな":    "ような",
+	"やうだ":    "ようだ",
+	"やうで":    "ようで",
+	"さう":     "そう",
+	"かうして":   "こうして",
+	"ありがたう":  "ありがとう",
+	"おはやう":   "おはよう",
+	"でせう":    "でしょう",
+	"ませう":    "ましょう",
+	"だらう":    "だろう",
+	"であらう":   "であろう",
+	"でありませう": "でありましょう",
+	"ゐ":      "い",
+	"ゑ":      "え",
+	"ヰ":      "イ",
+	"ヱ":      "エ",
+}
+
+// kanaAuxiliaries is kanaWords which follow inflected words (あるでせう, 行くやうに),
+// other words must start at the head of a word
+var kanaAuxiliaries = map[string]bool{
+	"やうに":    true,
+	"やうな":    true,
+	"やうだ":    true,
+	"やうで":    true,
+	"でせう":    true,
+	"ませう":    true,
+	"だらう":    true,
+	"であらう":   true,
+	"でありませう": true,
+}
+
+// kanaWordPreceding is hiragana particles which may precede a word (といふ)
+const kanaWordPreceding = "とはもがにをのでへやかよねてば"
+
+// kanaWordFollowing is hiragana of particles and inflections which may follow a word
+// (いふこと, さうして), いふく or かさうり does not contain the word
+const kanaWordFollowing = "いかがけこごしじすぞただってでとなにねのはばべへほまみもやゆよわをゐ"
+
+// kanaWordKeys is keys of kanaWords in descending order of length
+var kanaWordKeys = func() []string {
+	keys := make([]string, 0, len(kanaWords))
+	for k := range kanaWords {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[j]) < len(keys[i])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// haGyoInflection is hiragana that follows ひ, ふ and へ of inflected words
+// (思ひて, 言へば), ひ/ふ/へ between kanji and them are read as い/う/え,
+// ひ/ふ between kanji are also read as い/う (思ひ出) but へ is a particle (東京へ行く)
+var haGyoInflection = map[rune]string{
+	'ひ': "てまのもはが",
+	'へ': "ばどてられ",
+	'ふ': "とべにのもか",
+}
+
+var haGyoModern = map[rune]rune{'ひ': 'い', 'ふ': 'う', 'へ': 'え'}
+
+// ModernizeChange is a change of orthography, Col is 1-based and counts characters
+type ModernizeChange struct {
+	Line int
+	Col  int
+	From string
+	To   string
+}
+
+func (c ModernizeChange) String() string {
+	return fmt.Sprintf("%d:%d: %s -> %s", c.Line, c.Col, c.From, c.To)
+}
+
+// ModernizeEscaper converts 旧字体 into 新字体 by table, and historical kana
+// orthography into modern kana by rules (旧字旧仮名 -> 新字新仮名),
+// changes are recorded and not applied in dry run
+type ModernizeEscaper struct {
+	dryRun  bool
+	record  bool
+	changes []ModernizeChange
+}
+
+var (
+	_ LineEscaper = (*ModernizeEscaper)(nil)
+)
+
+func (e *ModernizeEscaper) Escape(src string) (string, bool) {
+	out, ok, _ := e.EscapeLine(Position{}, src)
+	return out, ok
+}
+
+// EscapeLine converts text, gaiji notations are not changed and quoted targets
+// of annotations are converted as the text they refer to (言ふ［＃「言ふ」に傍点］)
+func (e *ModernizeEscaper) EscapeLine(pos Position, src string) (string, bool, error) {
+	buf := new(strings.Builder)
+	buf.Grow(len(src))
+	// text without annotations and the converted text, bounds maps offset of text into converted
+	text, converted := new(strings.Builder), new(strings.Builder)
+	bounds := map[int]int{0: 0}
+	col := 1
+	var prev rune
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		if strings.HasPrefix(rest, notationGaijiOpen) {
+			if _, n, ok := annotationBody(rest[len(notationGaijiOpen):]); ok {
+				gaiji := rest[:len(notationGaijiOpen)+n]
+				buf.WriteString(gaiji)
+				text.WriteString(gaiji)
+				converted.WriteString(gaiji)
+				bounds[text.Len()] = converted.Len()
+				prev = '※'
+				col += utf8.RuneCountInString(gaiji)
+				i += len(gaiji)
+				continue
+			}
+		}
+		if strings.HasPrefix(rest, notationAnnotationOpen) {
+			if body, n, ok := annotationBody(rest[len(notationAnnotationOpen):]); ok {
+				buf.WriteString(notationAnnotationOpen)
+				buf.WriteString(e.annotation(body, text.String(), converted.String(), bounds))
+				buf.WriteString(notationBracketClose)
+				col += utf8.RuneCountInString(rest[:len(notationAnnotationOpen)+n])
+				i += len(notationAnnotationOpen) + n
+				continue
+			}
+		}
+
+		from, to := e.match(rest, prev)
+		if from == "" {
+			_, size := utf8.DecodeRuneInString(rest)
+			from, to = rest[:size], rest[:size]
+		} else if e.record {
+			e.changes = append(e.changes, ModernizeChange{Line: pos.Line, Col: col, From: from, To: to})
+		}
+		if e.dryRun {
+			to = from
+		}
+		buf.WriteString(to)
+		text.WriteString(from)
+		converted.WriteString(to)
+		bounds[text.Len()] = converted.Len()
+		prev, _ = utf8.DecodeLastRuneInString(from)
+		col += utf8.RuneCountInString(from)
+		i += len(from)
+	}
+	return buf.String(), true, nil
+}
+
+// annotation converts quoted targets in annotation body, a target is converted
+// in the same way as the last text it refers to so that it still matches the text
+func (e *ModernizeEscaper) annotation(body, text, converted string, bounds map[int]int) string {
+	if e.dryRun {
+		return body
+	}
+	return quotedTarget.ReplaceAllStringFunc(body, func(m string) string {
+		target := m[len("「") : len(m)-len("」")]
+		if i := strings.LastIndex(text, target); 0 <= i {
+			start, ok := bounds[i]
+			end, ok2 := bounds[i+len(target)]
+			if ok && ok2 {
+				return "「" + converted[start:end] + "」"
+			}
+		}
+		return "「" + e.convert(target) + "」"
+	})
+}
+
+// convert converts s without recording changes
+func (e *ModernizeEscaper) convert(s string) string {
+	buf := new(strings.Builder)
+	buf.Grow(len(s))
+	var prev rune
+	for i := 0; i < len(s); {
+		from, to := e.match(s[i:], prev)
+		if from == "" {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			buf.WriteString(s[i : i+size])
+			prev = r
+			i += size
+			continue
+		}
+		buf.WriteString(to)
+		prev, _ = utf8.DecodeLastRuneInString(from)
+		i += len(from)
+	}
+	return buf.String()
+}
+
+// match returns text at the head of s to be changed and the replacement
+func (e *ModernizeEscaper) match(s string, prev rune) (string, string) {
+	r, size := utf8.DecodeRuneInString(s)
+	if n, ok := kyujitaiTable[r]; ok {
+		return s[:size], string(n)
+	}
+	for _, k := range kanaWordKeys {
+		if strings.HasPrefix(s, k) && isKanaWordBoundary(k, prev, s[len(k):]) {
+			return k, kanaWords[k]
+		}
+	}
+	if following, ok := haGyoInflection[r]; ok && unicode.Is(unicode.Han, prev) {
+		next, _ := utf8.DecodeRuneInString(s[size:])
+		if strings.ContainsRune(following, next) {
+			return s[:size], string(haGyoModern[r])
+		}
+		// へ before punctuation or kanji is a particle (学校へ、)
+		if r != 'へ' && (next == utf8.RuneError || unicode.Is(unicode.Han, next) || strings.ContainsRune("。、」』）　\r\n", next)) {
+			return s[:size], string(haGyoModern[r])
+		}
+	}
+	return "", ""
+}
+
+// isKanaWordBoundary reports whether word is between prev and rest as a word (or an auxiliary),
+// a word of one character (ゐ) is always matched
+func isKanaWordBoundary(word string, prev rune, rest string) bool {
+	if utf8.RuneCountInString(word) == 1 {
+		return true
+	}
+	if kanaAuxiliaries[word] != true && unicode.Is(unicode.Hiragana, prev) && strings.ContainsRune(kanaWordPreceding, prev) != true {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	if unicode.Is(unicode.Hiragana, next) && strings.ContainsRune(kanaWordFollowing, next) != true {
+		return false
+	}
+	return true
+}
+
+// Changes returns changes recorded so far
+func (e *ModernizeEscaper) Changes() []ModernizeChange {
+	return append([]ModernizeChange(nil), e.changes...)
+}
+
+func newModernizeEscaper(dryRun, record bool) *ModernizeEscaper {
+	return &ModernizeEscaper{
+		dryRun:  dryRun,
+		record:  record,
+		changes: make([]ModernizeChange, 0, 64),
+	}
+}
+
+// NewModernizeEscaper returns ModernizeEscaper that records changes,
+// text is not changed if dryRun is true
+func NewModernizeEscaper(dryRun bool) *ModernizeEscaper {
+	return newModernizeEscaper(dryRun, true)
+}
@@ -0,0 +1,88 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestModernizeEscaper(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"國の澤に學ぶ", "国の沢に学ぶ"},
+		{"てふてふが飛ぶ", "ちょうちょうが飛ぶ"},
+		{"けふは雨だといふ。", "きょうは雨だという。"},
+		{"ゐなかの思ひ出を言へば", "いなかの思い出を言えば"},
+		{"さう思ふと、東京へ行くやうに", "そう思うと、東京へ行くように"},
+		{"人ふたり", "人ふたり"},
+		{"ありがたうございませう\r\n", "ありがとうございましょう\r\n"},
+		{"学校へ、", "学校へ、"},
+		{"学校へ", "学校へ"},
+		{"東京へまゐります", "東京へまいります"},
+		{"東京へ來る", "東京へ来る"},
+		{"いふく", "いふく"},
+		{"かさうり", "かさうり"},
+		{"だいふくを食べる", "だいふくを食べる"},
+		{"※［＃「木＋國」、第4水準2-15-6］", "※［＃「木＋國」、第4水準2-15-6］"},
+		{"と言ふ［＃「言ふ」に傍点］", "と言ふ［＃「言ふ」に傍点］"},
+		{"と言ふ［＃「言ふ」に傍点］て", "と言ふ［＃「言ふ」に傍点］て"},
+		{"學校で讀む［＃「讀む」に傍点］", "学校で読む［＃「読む」に傍点］"},
+		{"思ひ出［＃「思ひ出」は太字］", "思い出［＃「思い出」は太字］"},
+		{"［＃「戰場」のキャプション付きの挿絵（fig1_01.png）入る］", "［＃「戦場」のキャプション付きの挿絵（fig1_01.png）入る］"},
+	}
+	for _, tc := range tests {
+		actual, ok := NewModernizeEscaper(false).Escape(tc.in)
+		if ok != true {
+			t.Errorf("always true")
+		}
+		if actual != tc.expect {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, tc.expect, actual)
+		}
+	}
+}
+
+func TestModernizeEscaperDryRun(t *testing.T) {
+	e := NewModernizeEscaper(true)
+	out := bytes.NewBuffer(nil)
+	in := "本文\r\n國の澤といふ\r\n"
+	if err := Conv(out, strings.NewReader(in), WithEscaperAfter(StageModernize, e)); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if out.String() != in {
+		t.Errorf("dry run does not change text: actual=%s", out.String())
+	}
+	expect := []ModernizeChange{
+		{Line: 2, Col: 1, From: "國", To: "国"},
+		{Line: 2, Col: 3, From: "澤", To: "沢"},
+		{Line: 2, Col: 5, From: "いふ", To: "いう"},
+	}
+	if reflect.DeepEqual(expect, e.Changes()) != true {
+		t.Errorf("expect=%v actual=%v", expect, e.Changes())
+	}
+
+	out.Reset()
+	if err := Conv(out, strings.NewReader(in), WithModernize()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if out.String() != "本文\r\n国の沢という\r\n" {
+		t.Errorf("actual=%s", out.String())
+	}
+}
+
+func TestModernizeEscaperDryRunNotation(t *testing.T) {
+	e := NewModernizeEscaper(true)
+	in := "※［＃「木＋國」、第4水準2-15-6］と言ふ［＃「言ふ」に傍点］學［＃「學」に傍点］"
+	out, _ := e.Escape(in)
+	if out != in {
+		t.Errorf("dry run does not change text: actual=%s", out)
+	}
+	expect := []ModernizeChange{
+		{Line: 0, Col: 34, From: "學", To: "学"},
+	}
+	if reflect.DeepEqual(expect, e.Changes()) != true {
+		t.Errorf("expect=%v actual=%v", expect, e.Changes())
+	}
+}
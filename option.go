@@ -2,11 +2,42 @@ package aozoraconv
 
 type OptionFunc func(*option)
 
+// EscaperStage is a position of built-in escaper in the escaper chain
+type EscaperStage int
+
+const (
+	StageNotation EscaperStage = iota
+	StageNormalize
+	StageIVS
+	StageKanbun
+	StageGaiji
+	StageModernize
+	StageWidth
+	StageReading
+	StageRuby
+	StageImage
+	StageAnnotation
+	StageRepeatTwo
+)
+
 type option struct {
 	Header     Escaper
 	Ruby       Escaper
 	Annotation Escaper
 	RepeatTwo  Escaper
+	Gaiji      Escaper
+	Image      Escaper
+	Kanbun     Escaper
+	Notation   Escaper
+	Reading    Escaper
+	Modernize  Escaper
+	Width      Escaper
+	Normalize  Escaper
+	IVS        Escaper
+	SourceMap  *SourceMap
+	Before     map[EscaperStage][]Escaper
+	After      map[EscaperStage][]Escaper
+	Escapers   []Escaper
 }
 
 func WithoutHeader() OptionFunc {
@@ -33,12 +64,168 @@ func WithoutRepeatTwo() OptionFunc {
 	}
 }
 
+func WithResolveGaiji() OptionFunc {
+	return func(opt *option) {
+		opt.Gaiji = newGaijiEscaper()
+	}
+}
+
+// WithImagePlaceholder replaces illustration annotations with placeholder (e.g. "［挿絵：caption］")
+func WithImagePlaceholder() OptionFunc {
+	return func(opt *option) {
+		opt.Image = newImageEscaper()
+	}
+}
+
+// WithKanbunReorder reorders lines of classical chinese with kaeriten into
+// the reading order (書き下し), notations in those lines are removed
+func WithKanbunReorder() OptionFunc {
+	return func(opt *option) {
+		opt.Kanbun = newKanbunEscaper()
+	}
+}
+
+// WithRubyReading replaces ruby bases with readings (漢字《かんじ》 -> かんじ) for
+// text-to-speech, annotations are removed and repeat marks are expanded
+func WithRubyReading() OptionFunc {
+	return func(opt *option) {
+		opt.Reading = newReadingEscaper(false)
+	}
+}
+
+// WithRubyReadingKatakana is WithRubyReading that converts readings into katakana
+func WithRubyReadingKatakana() OptionFunc {
+	return func(opt *option) {
+		opt.Reading = newReadingEscaper(true)
+	}
+}
+
+// WithModernize converts 旧字旧仮名 into 新字新仮名 (國 -> 国, いふ -> いう),
+// use NewModernizeEscaper with WithEscaperAfter(StageModernize, ...) to get the report of changes
+func WithModernize() OptionFunc {
+	return func(opt *option) {
+		opt.Modernize = newModernizeEscaper(false, false)
+	}
+}
+
+// WithFullWidth converts ASCII into full-width and half-width katakana into
+// full-width (ｶﾞ -> ガ) as Aozora Bunko body text, texts in 縦中横 and 横組み are kept
+func WithFullWidth() OptionFunc {
+	return func(opt *option) {
+		opt.Width = newWidthEscaper(false)
+	}
+}
+
+// WithHalfWidthAlnum converts full-width alphanumerics into ASCII and half-width
+// katakana into full-width
+func WithHalfWidthAlnum() OptionFunc {
+	return func(opt *option) {
+		opt.Width = newWidthEscaper(true)
+	}
+}
+
+// WithNFC normalizes text into NFC (e.g. combining voiced sound mark か\u3099 -> が),
+// CJK compatibility ideographs are kept unless WithCompatIdeograph is given
+func WithNFC() OptionFunc {
+	return func(opt *option) {
+		policy := CompatPreserve
+		if n, ok := opt.Normalize.(*normalizeEscaper); ok {
+			policy = n.policy
+		}
+		opt.Normalize = newNormalizeEscaper(true, policy)
+	}
+}
+
+// WithCompatIdeograph handles CJK compatibility ideographs (e.g. 豈 U+F900, 塚 U+FA10) by policy
+func WithCompatIdeograph(policy CompatPolicy) OptionFunc {
+	return func(opt *option) {
+		nfc := false
+		if n, ok := opt.Normalize.(*normalizeEscaper); ok {
+			nfc = n.nfc
+		}
+		opt.Normalize = newNormalizeEscaper(nfc, policy)
+	}
+}
+
+// WithIVS maps variation sequences into CJK compatibility ideographs if the
+// variant is in JIS X 0213 (U+585A U+FE00 -> 塚 U+FA10), otherwise selectors are removed
+func WithIVS() OptionFunc {
+	return func(opt *option) {
+		opt.IVS = newIVSEscaper(false)
+	}
+}
+
+// WithIVSGaiji is WithIVS that replaces variation sequences which are not in JIS X 0213
+// with gaiji notation (※［＃「葛」、U+845B+E0101］)
+func WithIVSGaiji() OptionFunc {
+	return func(opt *option) {
+		opt.IVS = newIVSEscaper(true)
+	}
+}
+
+// WithStrictNotation stops conversion at malformed notation (e.g. unclosed ［＃ or 《)
+// and returns *NotationError which has the line and column
+func WithStrictNotation() OptionFunc {
+	return func(opt *option) {
+		opt.Notation = newNotationEscaper()
+	}
+}
+
+// WithSourceMap records positions of the source for each rune of converted text into m
+// (Conv, ConvRev and readers), positions of Decode are offsets of the decoded UTF-8 text
+func WithSourceMap(m *SourceMap) OptionFunc {
+	return func(opt *option) {
+		opt.SourceMap = m
+	}
+}
+
+// WithEscaper adds escaper to the end of escaper chain
+func WithEscaper(e Escaper) OptionFunc {
+	return WithEscapers(e)
+}
+
+// WithEscapers adds escapers to the end of escaper chain in the given order
+func WithEscapers(escapers ...Escaper) OptionFunc {
+	return func(opt *option) {
+		opt.Escapers = append(opt.Escapers, escapers...)
+	}
+}
+
+// WithEscaperBefore adds escapers just before the built-in escaper of stage
+// (e.g. StageRuby to replace words before ruby is removed), escapers are added
+// even if the built-in escaper is disabled
+func WithEscaperBefore(stage EscaperStage, escapers ...Escaper) OptionFunc {
+	return func(opt *option) {
+		opt.Before[stage] = append(opt.Before[stage], escapers...)
+	}
+}
+
+// WithEscaperAfter adds escapers just after the built-in escaper of stage
+func WithEscaperAfter(stage EscaperStage, escapers ...Escaper) OptionFunc {
+	return func(opt *option) {
+		opt.After[stage] = append(opt.After[stage], escapers...)
+	}
+}
+
 func defaultOption() *option {
 	return &option{
 		Header:     nil,
 		Ruby:       nil,
 		Annotation: nil,
 		RepeatTwo:  nil,
+		Gaiji:      nil,
+		Image:      nil,
+		Kanbun:     nil,
+		Notation:   nil,
+		Reading:    nil,
+		Modernize:  nil,
+		Width:      nil,
+		Normalize:  nil,
+		IVS:        nil,
+		SourceMap:  nil,
+		Before:     make(map[EscaperStage][]Escaper),
+		After:      make(map[EscaperStage][]Escaper),
+		Escapers:   nil,
 	}
 }
 
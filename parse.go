@@ -0,0 +1,529 @@
+package aozoraconv
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const (
+	PageBreakPage   = "改ページ"
+	PageBreakRecto  = "改丁"
+	PageBreakSpread = "改見開き"
+	PageBreakColumn = "改段"
+)
+
+const (
+	notationGaijiOpen      = "※［＃"
+	notationAnnotationOpen = "［＃"
+	notationBracketOpen    = "［"
+	notationBracketClose   = "］"
+	notationRubyIndex      = "｜"
+	notationRubyOpen       = "《"
+	notationRubyClose      = "》"
+	notationSeparator      = "-------------------------------------------------------"
+)
+
+var (
+	imageAnnotation = regexp.MustCompile(`^(?:「(.*)」のキャプション付きの)?(.*)（([^、）]+\.[0-9A-Za-z]+)(?:、横([0-9]+)×縦([0-9]+))?）入る$`)
+	gaijiBody       = regexp.MustCompile(`^「(.*?)」(?:、(.*))?$`)
+)
+
+// Node is an element of Aozora Bunko format text
+type Node interface {
+	aozoraNode()
+}
+
+var (
+	_ Node = (*Text)(nil)
+	_ Node = (*Ruby)(nil)
+	_ Node = (*Gaiji)(nil)
+	_ Node = (*Annotation)(nil)
+	_ Node = (*Image)(nil)
+	_ Node = (*PageBreak)(nil)
+	_ Node = (*Kanbun)(nil)
+)
+
+// Text is a plain text
+type Text struct {
+	Value string
+}
+
+// Ruby is a text with ruby (漢字《かんじ》 or ｜漢字《かんじ》)
+type Ruby struct {
+	Base     []Node
+	Reading  string
+	Explicit bool
+}
+
+// Gaiji is a character out of JIS X 0208 (※［＃「description」、第3水準1-85-9］)
+type Gaiji struct {
+	Description string
+	Note        string
+	Char        string
+}
+
+// Annotation is a uninterpreted annotation (［＃…］)
+type Annotation struct {
+	Body string
+}
+
+// Image is an illustration (［＃挿絵（fig1234_01.png、横320×縦480）入る］),
+// Description is free text before the file name (挿絵, 石鏃二つの図)
+type Image struct {
+	Description string
+	File        string
+	Width       int
+	Height      int
+	Caption     string
+}
+
+// PageBreak is a page or section break (［＃改ページ］, ［＃改丁］, ［＃改見開き］, ［＃改段］)
+type PageBreak struct {
+	Kind string
+}
+
+// Kanbun is a kaeriten (［＃二］, ［＃レ］) or okurigana (［＃（ノ）］) of classical chinese text
+type Kanbun struct {
+	Kaeriten  string
+	Okurigana string
+}
+
+func (*Text) aozoraNode()       {}
+func (*Ruby) aozoraNode()       {}
+func (*Gaiji) aozoraNode()      {}
+func (*Annotation) aozoraNode() {}
+func (*Image) aozoraNode()      {}
+func (*PageBreak) aozoraNode()  {}
+func (*Kanbun) aozoraNode()     {}
+
+// Line is a line of Aozora Bunko format text
+type Line struct {
+	Nodes []Node
+	EOL   string
+}
+
+// Document is a parsed Aozora Bunko format text
+type Document struct {
+	Lines []*Line
+}
+
+// Parse parses Aozora Bunko format text (UTF-8, CRLF)
+func Parse(r io.Reader) (*Document, error) {
+	doc := &Document{Lines: make([]*Line, 0, 1024)}
+	scan := NewAozoraTextScanner(r)
+	for scan.Scan() {
+		doc.Lines = append(doc.Lines, ParseLine(scan.Text()))
+	}
+	if err := scan.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return doc, nil
+}
+
+// ParseLine parses a line of Aozora Bunko format text
+func ParseLine(src string) *Line {
+	text, eol := src, ""
+	if strings.HasSuffix(text, "\r\n") {
+		text, eol = text[:len(text)-2], "\r\n"
+	}
+	p := &lineParser{
+		nodes:     make([]Node, 0, 8),
+		rubyStart: -1,
+	}
+	p.parse(text)
+	return &Line{Nodes: p.nodes, EOL: eol}
+}
+
+type lineParser struct {
+	nodes     []Node
+	text      strings.Builder
+	rubyStart int
+}
+
+func (p *lineParser) parse(src string) {
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, notationGaijiOpen):
+			if body, n, ok := annotationBody(rest[len(notationGaijiOpen):]); ok {
+				p.push(newGaiji(body))
+				i += len(notationGaijiOpen) + n
+				continue
+			}
+		case strings.HasPrefix(rest, notationAnnotationOpen):
+			if body, n, ok := annotationBody(rest[len(notationAnnotationOpen):]); ok {
+				if p.layout(body) != true {
+					p.push(newAnnotationNode(body))
+				}
+				i += len(notationAnnotationOpen) + n
+				continue
+			}
+		case strings.HasPrefix(rest, notationRubyIndex):
+			p.flush()
+			p.literalRubyIndex()
+			p.rubyStart = len(p.nodes)
+			i += len(notationRubyIndex)
+			continue
+		case strings.HasPrefix(rest, notationRubyOpen):
+			if n := strings.Index(rest, notationRubyClose); 0 < n {
+				reading := rest[len(notationRubyOpen):n]
+				if reading != "" && p.ruby(reading) {
+					i += n + len(notationRubyClose)
+					continue
+				}
+			}
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		p.text.WriteString(rest[:size])
+		i += size
+	}
+	p.flush()
+	p.literalRubyIndex()
+	p.nodes = mergeText(p.nodes)
+}
+
+func (p *lineParser) flush() {
+	if p.text.Len() < 1 {
+		return
+	}
+	p.nodes = append(p.nodes, &Text{Value: p.text.String()})
+	p.text.Reset()
+}
+
+func (p *lineParser) push(n Node) {
+	p.flush()
+	p.nodes = append(p.nodes, n)
+}
+
+// literalRubyIndex restores "｜" which is not followed by ruby
+func (p *lineParser) literalRubyIndex() {
+	if p.rubyStart < 0 {
+		return
+	}
+	nodes := make([]Node, 0, len(p.nodes)+1)
+	nodes = append(nodes, p.nodes[:p.rubyStart]...)
+	nodes = append(nodes, &Text{Value: notationRubyIndex})
+	nodes = append(nodes, p.nodes[p.rubyStart:]...)
+	p.nodes = nodes
+	p.rubyStart = -1
+}
+
+func (p *lineParser) ruby(reading string) bool {
+	p.flush()
+	if 0 <= p.rubyStart {
+		if p.rubyStart == len(p.nodes) {
+			return false
+		}
+		base := append([]Node(nil), p.nodes[p.rubyStart:]...)
+		p.nodes = append(p.nodes[:p.rubyStart], &Ruby{Base: mergeText(base), Reading: reading, Explicit: true})
+		p.rubyStart = -1
+		return true
+	}
+
+	nodes, base := splitRubyBase(p.nodes)
+	if len(base) < 1 {
+		return false
+	}
+	p.nodes = append(nodes, &Ruby{Base: base, Reading: reading})
+	return true
+}
+
+// annotationBody returns body of annotation and length of consumed bytes (includes "］")
+func annotationBody(src string) (string, int, bool) {
+	depth := 0
+	for i := 0; i < len(src); {
+		switch {
+		case strings.HasPrefix(src[i:], notationBracketOpen):
+			depth += 1
+			i += len(notationBracketOpen)
+			continue
+		case strings.HasPrefix(src[i:], notationBracketClose):
+			if depth == 0 {
+				return src[:i], i + len(notationBracketClose), true
+			}
+			depth -= 1
+			i += len(notationBracketClose)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(src[i:])
+		i += size
+	}
+	return "", 0, false
+}
+
+func newGaiji(body string) *Gaiji {
+	g := &Gaiji{Note: body}
+	m := gaijiBody.FindStringSubmatch(body)
+	if m == nil {
+		// gaiji without description (※［＃始め二重山括弧、1-1-52］)
+		g.Char = resolveGaiji(body)
+		return g
+	}
+	g.Description, g.Note = m[1], m[2]
+	g.Char = resolveGaiji(g.Note)
+	return g
+}
+
+// resolveGaiji returns character from gaiji note ("第3水準1-85-9", "U+5F45、13-3")
+func resolveGaiji(note string) string {
+	for _, field := range strings.Split(note, "、") {
+		if strings.HasPrefix(field, "U+") {
+			chr, ok := parseUnicodeSequence(field[2:])
+			if ok != true {
+				continue
+			}
+			return chr
+		}
+		if entry, err := ParseMenKuTen(field); err == nil {
+			// JIS X 0208 is the same character as Decode (1-1-35 is ｜, not |)
+			if Is0208(int(entry.Men), int(entry.Ku), int(entry.Ten)) {
+				if chr, err := japanese.ShiftJIS.NewDecoder().Bytes(Kuten2Sjis(int(entry.Ku), int(entry.Ten))); err == nil {
+					return string(chr)
+				}
+			}
+			if chr, err := entry.Unicode(); err == nil {
+				return chr
+			}
+		}
+	}
+	return ""
+}
+
+func newAnnotationNode(body string) Node {
+	switch body {
+	case PageBreakPage, PageBreakRecto, PageBreakSpread, PageBreakColumn:
+		return &PageBreak{Kind: body}
+	}
+	if k, ok := newKanbun(body); ok {
+		return k
+	}
+	if m := imageAnnotation.FindStringSubmatch(body); m != nil {
+		width, _ := strconv.Atoi(m[4])
+		height, _ := strconv.Atoi(m[5])
+		return &Image{
+			Description: m[2],
+			File:        m[3],
+			Width:       width,
+			Height:      height,
+			Caption:     m[1],
+		}
+	}
+	return &Annotation{Body: body}
+}
+
+func mergeText(nodes []Node) []Node {
+	merged := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		if t, ok := n.(*Text); ok {
+			if t.Value == "" {
+				continue
+			}
+			if 0 < len(merged) {
+				if prev, ok := merged[len(merged)-1].(*Text); ok {
+					merged[len(merged)-1] = &Text{Value: prev.Value + t.Value}
+					continue
+				}
+			}
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+type charClass int
+
+const (
+	classOther charClass = iota
+	classKanji
+	classHiragana
+	classKatakana
+	classFullwidthAlnum
+	classHalfwidthAlnum
+)
+
+func charClassOf(r rune) charClass {
+	switch {
+	case unicode.Is(unicode.Han, r), r == '々', r == '〆', r == '〇', r == 'ヶ', r == '〻':
+		return classKanji
+	case unicode.Is(unicode.Hiragana, r), r == 'ゝ', r == 'ゞ':
+		return classHiragana
+	case unicode.Is(unicode.Katakana, r), r == 'ー', r == 'ヽ', r == 'ヾ':
+		return classKatakana
+	case ('０' <= r && r <= '９') || ('Ａ' <= r && r <= 'Ｚ') || ('ａ' <= r && r <= 'ｚ'):
+		return classFullwidthAlnum
+	case ('0' <= r && r <= '9') || ('A' <= r && r <= 'Z') || ('a' <= r && r <= 'z'):
+		return classHalfwidthAlnum
+	}
+	return classOther
+}
+
+// splitRubyBase splits nodes into preceding nodes and implicit ruby base,
+// the base is the last run of characters of the same class (gaiji is treated as kanji)
+func splitRubyBase(nodes []Node) ([]Node, []Node) {
+	class := classOther
+	base := make([]Node, 0, 2)
+	i := len(nodes) - 1
+	for ; 0 <= i; i -= 1 {
+		switch n := nodes[i].(type) {
+		case *Gaiji:
+			if class != classOther && class != classKanji {
+				return nodes[:i+1], base
+			}
+			class = classKanji
+			base = append([]Node{n}, base...)
+			continue
+		case *Text:
+			value := n.Value
+			j := len(value)
+			for 0 < j {
+				r, size := utf8.DecodeLastRuneInString(value[:j])
+				c := charClassOf(r)
+				if c == classOther || (class != classOther && c != class) {
+					break
+				}
+				class = c
+				j -= size
+			}
+			if j == len(value) {
+				return nodes[:i+1], base
+			}
+			base = append([]Node{&Text{Value: value[j:]}}, base...)
+			if 0 < j {
+				rest := append(append([]Node(nil), nodes[:i]...), &Text{Value: value[:j]})
+				return rest, base
+			}
+			continue
+		}
+		break
+	}
+	return nodes[:i+1], base
+}
+
+// PlainText returns text of nodes without notation
+func PlainText(nodes []Node) string {
+	buf := new(strings.Builder)
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			buf.WriteString(v.Value)
+		case *Ruby:
+			buf.WriteString(PlainText(v.Base))
+		case *Layout:
+			buf.WriteString(PlainText(v.Nodes))
+		case *Gaiji:
+			if v.Char != "" {
+				buf.WriteString(v.Char)
+			} else {
+				buf.WriteString("※")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Split splits lines into header (title, author and notation explanation),
+// body and footer (bibliographic information from "底本：")
+func (d *Document) Split() (header, body, footer []*Line) {
+	bodyStart := 0
+	separators := 0
+	for i, l := range d.Lines {
+		if len(l.Nodes) == 1 {
+			if t, ok := l.Nodes[0].(*Text); ok && t.Value == notationSeparator {
+				separators += 1
+				if separators == 2 {
+					bodyStart = i + 1
+					break
+				}
+			}
+		}
+	}
+	if separators < 2 {
+		bodyStart = 0
+	}
+
+	bodyEnd := len(d.Lines)
+	for i := len(d.Lines) - 1; bodyStart <= i; i -= 1 {
+		if t, ok := firstText(d.Lines[i]); ok && footerPattern.MatchString(t) {
+			bodyEnd = i
+			break
+		}
+	}
+	return d.Lines[:bodyStart], d.Lines[bodyStart:bodyEnd], d.Lines[bodyEnd:]
+}
+
+// Title returns title and author from the leading lines (before the first empty line)
+func (d *Document) Title() (title, author string) {
+	header, _, _ := d.Split()
+	if len(header) < 1 {
+		header = d.Lines
+	}
+	lines := make([]string, 0, 4)
+	for _, l := range header {
+		text := PlainText(l.Nodes)
+		if text == "" {
+			break
+		}
+		lines = append(lines, text)
+	}
+	if len(lines) < 1 {
+		return "", ""
+	}
+	if len(lines) < 2 {
+		return lines[0], ""
+	}
+	return lines[0], lines[len(lines)-1]
+}
+
+func firstText(l *Line) (string, bool) {
+	if len(l.Nodes) < 1 {
+		return "", false
+	}
+	t, ok := l.Nodes[0].(*Text)
+	if ok != true {
+		return "", false
+	}
+	return t.Value, true
+}
+
+// Section is a part of document separated by page breaks
+type Section struct {
+	Break *PageBreak
+	Lines []*Line
+}
+
+// Sections splits lines at page breaks, a line that has only page break is
+// not included in sections, a page break in the middle of line splits the line
+func (d *Document) Sections() []*Section {
+	current := &Section{Break: nil, Lines: make([]*Line, 0, len(d.Lines))}
+	sections := []*Section{current}
+	for _, l := range d.Lines {
+		last := 0
+		for i, n := range l.Nodes {
+			pb, ok := n.(*PageBreak)
+			if ok != true {
+				continue
+			}
+			if last < i {
+				current.Lines = append(current.Lines, &Line{Nodes: l.Nodes[last:i], EOL: l.EOL})
+			}
+			current = &Section{Break: pb, Lines: make([]*Line, 0, 64)}
+			sections = append(sections, current)
+			last = i + 1
+		}
+		if last == 0 {
+			current.Lines = append(current.Lines, l)
+			continue
+		}
+		if last < len(l.Nodes) {
+			current.Lines = append(current.Lines, &Line{Nodes: l.Nodes[last:], EOL: l.EOL})
+		}
+	}
+	return sections
+}
@@ -0,0 +1,324 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseLineTests = []struct {
+	in     string
+	expect *Line
+}{
+	{
+		in:     "停車場\r\n",
+		expect: &Line{Nodes: []Node{&Text{"停車場"}}, EOL: "\r\n"},
+	},
+	{
+		in:     "",
+		expect: &Line{Nodes: []Node{}, EOL: ""},
+	},
+	{
+		in: "下宿屋は蚊帳《かや》や蒲団《ふとん》を乾して居る",
+		expect: &Line{Nodes: []Node{
+			&Text{"下宿屋は"},
+			&Ruby{Base: []Node{&Text{"蚊帳"}}, Reading: "かや"},
+			&Text{"や"},
+			&Ruby{Base: []Node{&Text{"蒲団"}}, Reading: "ふとん"},
+			&Text{"を乾して居る"},
+		}},
+	},
+	{
+		in: "晩｜停車場《ステーション》",
+		expect: &Line{Nodes: []Node{
+			&Text{"晩"},
+			&Ruby{Base: []Node{&Text{"停車場"}}, Reading: "ステーション", Explicit: true},
+		}},
+	},
+	{
+		in: "カタカナ《かたかな》とＡＢＣ《えーびーしー》",
+		expect: &Line{Nodes: []Node{
+			&Ruby{Base: []Node{&Text{"カタカナ"}}, Reading: "かたかな"},
+			&Text{"と"},
+			&Ruby{Base: []Node{&Text{"ＡＢＣ"}}, Reading: "えーびーしー"},
+		}},
+	},
+	{
+		in: "その※［＃「足へん＋宛」、第3水準1-92-36］き《もがき》",
+		expect: &Line{Nodes: []Node{
+			&Text{"その"},
+			&Gaiji{Description: "足へん＋宛", Note: "第3水準1-92-36", Char: "踠"},
+			&Ruby{Base: []Node{&Text{"き"}}, Reading: "もがき"},
+		}},
+	},
+	{
+		in: "諸※［＃「木＋世」、U+67BB、13-3］《もろもろ》",
+		expect: &Line{Nodes: []Node{
+			&Ruby{Base: []Node{
+				&Text{"諸"},
+				&Gaiji{Description: "木＋世", Note: "U+67BB、13-3", Char: "枻"},
+			}, Reading: "もろもろ"},
+		}},
+	},
+	{
+		in: "※［＃「てへん＋劣」、206-4］",
+		expect: &Line{Nodes: []Node{
+			&Gaiji{Description: "てへん＋劣", Note: "206-4", Char: ""},
+		}},
+	},
+	{
+		in: "※［＃始め二重山括弧、1-1-52］※［＃米印、1-2-8］",
+		expect: &Line{Nodes: []Node{
+			&Gaiji{Description: "", Note: "始め二重山括弧、1-1-52", Char: "《"},
+			&Gaiji{Description: "", Note: "米印、1-2-8", Char: "※"},
+		}},
+	},
+	{
+		in: "｜：ルビの付く文字列の始まりを特定する記号",
+		expect: &Line{Nodes: []Node{
+			&Text{"｜：ルビの付く文字列の始まりを特定する記号"},
+		}},
+	},
+	{
+		in: "《》：ルビ",
+		expect: &Line{Nodes: []Node{
+			&Text{"《》：ルビ"},
+		}},
+	},
+	{
+		in: "［＃７字下げ］二［＃「二」は中見出し］",
+		expect: &Line{Nodes: []Node{
+			&Annotation{Body: "７字下げ"},
+			&Text{"二"},
+			&Annotation{Body: "「二」は中見出し"},
+		}},
+	},
+	{
+		in: "［＃］：入力者注　［＃閉じていない",
+		expect: &Line{Nodes: []Node{
+			&Annotation{Body: ""},
+			&Text{"：入力者注　［＃閉じていない"},
+		}},
+	},
+}
+
+func TestParseLine(t *testing.T) {
+	for _, tc := range parseLineTests {
+		actual := ParseLine(tc.in)
+		if reflect.DeepEqual(tc.expect, actual) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes(tc.expect.Nodes), dumpNodes(actual.Nodes))
+		}
+	}
+}
+
+var parseImageTests = []struct {
+	in     string
+	expect Node
+}{
+	{
+		in:     "［＃挿絵（fig1234_01.png、横320×縦480）入る］",
+		expect: &Image{Description: "挿絵", File: "fig1234_01.png", Width: 320, Height: 480},
+	},
+	{
+		in:     "［＃「停車場の図」のキャプション付きの図（fig1234_02.png、横200×縦100）入る］",
+		expect: &Image{Description: "図", File: "fig1234_02.png", Width: 200, Height: 100, Caption: "停車場の図"},
+	},
+	{
+		in:     "［＃（fig1234_03.png）入る］",
+		expect: &Image{Description: "", File: "fig1234_03.png"},
+	},
+	{
+		in:     "［＃石鏃二つの図（fig42154_01.png、横321×縦123）入る］",
+		expect: &Image{Description: "石鏃二つの図", File: "fig42154_01.png", Width: 321, Height: 123},
+	},
+	{
+		in:     "［＃「石鏃」のキャプション付きの石鏃二つの図（fig42154_02.png）入る］",
+		expect: &Image{Description: "石鏃二つの図", File: "fig42154_02.png", Caption: "石鏃"},
+	},
+}
+
+func TestParseImage(t *testing.T) {
+	for _, tc := range parseImageTests {
+		actual := ParseLine(tc.in)
+		if len(actual.Nodes) != 1 || reflect.DeepEqual(tc.expect, actual.Nodes[0]) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes([]Node{tc.expect}), dumpNodes(actual.Nodes))
+		}
+	}
+}
+
+func TestDocumentSplit(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"本文",
+		"",
+		"",
+		"",
+		"底本：「真山青果全集」",
+		"入力：",
+		"",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	header, body, footer := doc.Split()
+	if len(header) != 8 || len(body) != 4 || len(footer) != 2 {
+		t.Errorf("header=%d body=%d footer=%d", len(header), len(body), len(footer))
+	}
+	title, author := doc.Title()
+	if title != "茗荷畠" || author != "眞山青果" {
+		t.Errorf("title=%s author=%s", title, author)
+	}
+
+	doc, err = Parse(strings.NewReader("本文\r\n"))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	header, body, footer = doc.Split()
+	if len(header) != 0 || len(body) != 1 || len(footer) != 0 {
+		t.Errorf("header=%d body=%d footer=%d", len(header), len(body), len(footer))
+	}
+}
+
+var parseLayoutTests = []struct {
+	in     string
+	expect []Node
+}{
+	{
+		in: "昭和10［＃「10」は縦中横］年",
+		expect: []Node{
+			&Text{"昭和"},
+			&Layout{Kind: LayoutTatechuyoko, Nodes: []Node{&Text{"10"}}, Reference: true},
+			&Text{"年"},
+		},
+	},
+	{
+		in: "本文［＃割り注］注記｜東京《とうきょう》［＃割り注終わり］続き",
+		expect: []Node{
+			&Text{"本文"},
+			&Layout{Kind: LayoutWarichu, Nodes: []Node{
+				&Text{"注記"},
+				&Ruby{Base: []Node{&Text{"東京"}}, Reading: "とうきょう", Explicit: true},
+			}},
+			&Text{"続き"},
+		},
+	},
+	{
+		in: "Ｈ２［＃「２」は下付き小文字］Ｏとｘ［＃上付き小文字］２［＃上付き小文字終わり］",
+		expect: []Node{
+			&Text{"Ｈ"},
+			&Layout{Kind: LayoutSubscript, Nodes: []Node{&Text{"２"}}, Reference: true},
+			&Text{"Ｏとｘ"},
+			&Layout{Kind: LayoutSuperscript, Nodes: []Node{&Text{"２"}}},
+		},
+	},
+	{
+		in: "東京《とうきょう》駅［＃「東京駅」は罫囲み］",
+		expect: []Node{
+			&Layout{Kind: LayoutKeigakomi, Nodes: []Node{
+				&Ruby{Base: []Node{&Text{"東京"}}, Reading: "とうきょう"},
+				&Text{"駅"},
+			}, Reference: true},
+		},
+	},
+	{
+		in: "［＃ここから横組み］",
+		expect: []Node{
+			&LayoutStart{Kind: LayoutYokogumi, Block: true},
+		},
+	},
+	{
+		in: "前［＃小書き］あ",
+		expect: []Node{
+			&Text{"前"},
+			&LayoutStart{Kind: LayoutKogaki},
+			&Text{"あ"},
+		},
+	},
+	{
+		in: "い［＃ここで横組み終わり］",
+		expect: []Node{
+			&Text{"い"},
+			&LayoutEnd{Kind: LayoutYokogumi, Block: true},
+		},
+	},
+	{
+		in: "本文［＃「なし」は縦中横］",
+		expect: []Node{
+			&Text{"本文"},
+			&Annotation{Body: "「なし」は縦中横"},
+		},
+	},
+}
+
+func TestParseLayout(t *testing.T) {
+	for _, tc := range parseLayoutTests {
+		actual := ParseLine(tc.in)
+		if reflect.DeepEqual(tc.expect, actual.Nodes) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes(tc.expect), dumpNodes(actual.Nodes))
+		}
+	}
+}
+
+func dumpNodes(nodes []Node) string {
+	buf := new(strings.Builder)
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Ruby:
+			fmt.Fprintf(buf, "&Ruby{Base:[%s] Reading:%s Explicit:%v} ", dumpNodes(v.Base), v.Reading, v.Explicit)
+		case *Layout:
+			fmt.Fprintf(buf, "&Layout{Kind:%s Nodes:[%s] Reference:%v} ", v.Kind, dumpNodes(v.Nodes), v.Reference)
+		default:
+			fmt.Fprintf(buf, "%+v ", n)
+		}
+	}
+	return buf.String()
+}
+
+func TestDocumentSections(t *testing.T) {
+	in := strings.Join([]string{
+		"一",
+		"［＃改ページ］",
+		"二",
+		"前［＃改丁］後",
+		"［＃改見開き］",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	sections := doc.Sections()
+	if len(sections) != 4 {
+		t.Fatalf("sections=%d", len(sections))
+	}
+	expects := []struct {
+		kind  string
+		lines []string
+	}{
+		{"", []string{"一"}},
+		{PageBreakPage, []string{"二", "前"}},
+		{PageBreakRecto, []string{"後"}},
+		{PageBreakSpread, []string{}},
+	}
+	for i, expect := range expects {
+		sec := sections[i]
+		if (sec.Break == nil && expect.kind != "") || (sec.Break != nil && sec.Break.Kind != expect.kind) {
+			t.Errorf("[%d] break=%+v expect=%s", i, sec.Break, expect.kind)
+		}
+		lines := make([]string, 0, len(sec.Lines))
+		for _, l := range sec.Lines {
+			lines = append(lines, PlainText(l.Nodes))
+		}
+		if reflect.DeepEqual(expect.lines, lines) != true {
+			t.Errorf("[%d] expect=%v actual=%v", i, expect.lines, lines)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package aozoraconv
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// convReader converts lines of text when it is read
+type convReader struct {
+	scan    *bufio.Scanner
+	esc     Escaper
+	smap    *SourceMap
+	pos     Position
+	replace func(string) string
+	reverse func(string) string
+	buf     []byte
+	err     error
+}
+
+func (c *convReader) Read(p []byte) (int, error) {
+	for len(c.buf) < 1 && c.err == nil {
+		c.fill()
+	}
+	if 0 < len(c.buf) {
+		n := copy(p, c.buf)
+		c.buf = c.buf[n:]
+		return n, nil
+	}
+	return 0, c.err
+}
+
+// fill converts next line into buf
+func (c *convReader) fill() {
+	if c.scan.Scan() != true {
+		if err := c.scan.Err(); err != nil {
+			c.err = errors.WithStack(err)
+			return
+		}
+		c.err = io.EOF
+		return
+	}
+
+	text := c.scan.Text()
+	c.pos.Line += 1
+	source := c.replace(text)
+	newText, ok, err := escapeLine(c.esc, c.pos, source)
+	if err != nil {
+		var ne *NotationError
+		if errors.As(err, &ne) {
+			// replacement changes byte length of characters but not the number of characters
+			ne.Offset = c.pos.Offset + int64(runeIndexToByte(text, ne.Col-1))
+		}
+		c.err = errors.WithStack(err)
+		return
+	}
+	if c.smap != nil {
+		if ok != true {
+			newText = ""
+		}
+		c.smap.addLine(c.pos.Offset, text, source, newText)
+	}
+	c.pos.Offset += int64(len(text))
+	if ok != true {
+		return
+	}
+	c.buf = append(c.buf[:0], c.reverse(newText)...)
+}
+
+// runeIndexToByte returns byte index of n-th character of s
+func runeIndexToByte(s string, n int) int {
+	count := 0
+	for i := range s {
+		if count == n {
+			return i
+		}
+		count += 1
+	}
+	return len(s)
+}
+
+func noReplace(s string) string {
+	return s
+}
+
+func newConvReader(r io.Reader, replace, reverse func(string) string, opts ...OptionFunc) *convReader {
+	opt := newOption(opts...)
+	return &convReader{
+		scan:    NewAozoraTextScanner(r),
+		esc:     NewEscape(opt),
+		smap:    opt.SourceMap,
+		pos:     Position{Line: 0, Offset: 0},
+		replace: replace,
+		reverse: reverse,
+		buf:     make([]byte, 0, 1024),
+		err:     nil,
+	}
+}
+
+// NewReader returns io.Reader that yields text converted by Conv,
+// lines are converted lazily when it is read
+func NewReader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return newConvReader(r, aozoraUtf8CharReplacer.Replace, noReplace, opts...)
+}
+
+// NewRevReader returns io.Reader that yields text converted by ConvRev
+func NewRevReader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return newConvReader(r, noReplace, aozoraUtf8CharReplacerR.Replace, opts...)
+}
+
+// NewDecodeReader returns io.Reader that yields UTF-8 text converted by Decode from Shift_JIS
+func NewDecodeReader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return NewRevReader(transform.NewReader(r, japanese.ShiftJIS.NewDecoder()), opts...)
+}
+
+// NewEncodeReader returns io.Reader that yields Shift_JIS text converted by Encode from UTF-8
+func NewEncodeReader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return transform.NewReader(NewReader(r, opts...), japanese.ShiftJIS.NewEncoder())
+}
+
+// NewDecode2004Reader returns io.Reader that yields UTF-8 text converted by Decode2004 from Shift_JIS-2004
+func NewDecode2004Reader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return newConvReader(transform.NewReader(r, ShiftJIS2004.NewDecoder()), noReplace, noReplace, opts...)
+}
+
+// NewEncode2004Reader returns io.Reader that yields Shift_JIS-2004 text converted by Encode2004 from UTF-8
+func NewEncode2004Reader(r io.Reader, opts ...OptionFunc) io.Reader {
+	return transform.NewReader(newConvReader(r, noReplace, noReplace, withLastEscapers(opts, newIVSEscaper(false))...), ShiftJIS2004.NewEncoder())
+}
@@ -0,0 +1,61 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	in := "晩｜停車場《ステーション》で\r\n頭をフラ／＼\r\n〜\r\n"
+
+	t.Run("same as Conv", func(tt *testing.T) {
+		expect := bytes.NewBuffer(nil)
+		if err := Conv(expect, strings.NewReader(in), WithoutRuby(), WithoutRepeatTwo()); err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		actual, err := io.ReadAll(iotest.OneByteReader(NewReader(strings.NewReader(in), WithoutRuby(), WithoutRepeatTwo())))
+		if err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		if string(actual) != expect.String() || string(actual) != "晩停車場で\r\n頭をフラフラ\r\n～\r\n" {
+			tt.Errorf("expect=%q actual=%q", expect.String(), actual)
+		}
+	})
+	t.Run("limit", func(tt *testing.T) {
+		actual, err := io.ReadAll(io.LimitReader(NewReader(strings.NewReader(in), WithoutRuby()), int64(len("晩停車場"))))
+		if err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		if string(actual) != "晩停車場" {
+			tt.Errorf("actual=%q", actual)
+		}
+	})
+	t.Run("error", func(tt *testing.T) {
+		r := NewReader(strings.NewReader("一行目\r\n停車場《\r\n三行目\r\n"), WithStrictNotation())
+		actual, err := io.ReadAll(r)
+		var ne *NotationError
+		if errors.As(err, &ne) != true || ne.Line != 2 {
+			tt.Errorf("expect NotationError at line 2: %v", err)
+		}
+		if string(actual) != "一行目\r\n" {
+			tt.Errorf("lines before error are read: actual=%q", actual)
+		}
+	})
+	t.Run("encode and decode", func(tt *testing.T) {
+		sjis, err := io.ReadAll(NewEncodeReader(strings.NewReader(in)))
+		if err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		utf8, err := io.ReadAll(NewDecodeReader(bytes.NewReader(sjis)))
+		if err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		if string(utf8) != in {
+			tt.Errorf("expect=%q actual=%q", in, utf8)
+		}
+	})
+}
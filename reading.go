@@ -0,0 +1,93 @@
+package aozoraconv
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	repeatTwoVoiced = regexp.MustCompile(`([^／]{2})(／″＼)`)
+)
+
+const combiningVoicedMark = "\u3099"
+
+// readingEscaper replaces ruby bases with readings (漢字《かんじ》 -> かんじ),
+// removes annotations and expands repeat marks
+type readingEscaper struct {
+	katakana bool
+}
+
+func (e *readingEscaper) Escape(src string) (string, bool) {
+	line := ParseLine(src)
+	buf := new(strings.Builder)
+	e.writeNodes(buf, line.Nodes)
+	return expandRepeatMarks(buf.String()) + line.EOL, true
+}
+
+func (e *readingEscaper) writeNodes(buf *strings.Builder, nodes []Node) {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			buf.WriteString(v.Value)
+		case *Ruby:
+			if e.katakana {
+				buf.WriteString(hiraganaToKatakana(v.Reading))
+			} else {
+				buf.WriteString(v.Reading)
+			}
+		case *Gaiji:
+			buf.WriteString(v.Char)
+		case *Layout:
+			e.writeNodes(buf, v.Nodes)
+		case *Kanbun:
+			buf.WriteString(katakanaToHiragana(v.Okurigana))
+		}
+	}
+}
+
+func newReadingEscaper(katakana bool) *readingEscaper {
+	return &readingEscaper{
+		katakana: katakana,
+	}
+}
+
+// NewReadingEscaper returns Escaper that replaces ruby bases with readings,
+// removes annotations and expands repeat marks
+func NewReadingEscaper(katakana bool) Escaper {
+	return newReadingEscaper(katakana)
+}
+
+// expandRepeatMarks expands repeat marks of two or more characters (／＼ and ／″＼)
+func expandRepeatMarks(s string) string {
+	if strings.Contains(s, "＼") != true {
+		return s
+	}
+	s = repeatTwoVoiced.ReplaceAllStringFunc(s, func(m string) string {
+		chars := strings.TrimSuffix(m, "／″＼")
+		return chars + voiced(chars)
+	})
+	return repeatTwo.ReplaceAllString(s, "$1$1")
+}
+
+// voiced returns s with the voiced sound mark on the first character if it can be composed
+func voiced(s string) string {
+	for _, r := range s {
+		composed := norm.NFC.String(string(r) + combiningVoicedMark)
+		if len([]rune(composed)) != 1 {
+			return s
+		}
+		return composed + s[len(string(r)):]
+	}
+	return s
+}
+
+func hiraganaToKatakana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'ぁ' <= r && r <= 'ゖ' {
+			return r + ('ァ' - 'ぁ')
+		}
+		return r
+	}, s)
+}
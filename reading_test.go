@@ -0,0 +1,42 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEscaperReading(t *testing.T) {
+	tests := []struct {
+		in       string
+		katakana bool
+		expect   string
+	}{
+		{"下宿屋は蚊帳《かや》や蒲団《ふとん》を乾して居る\r\n", false, "下宿屋はかややふとんを乾して居る\r\n"},
+		{"晩｜停車場《ステーション》で［＃「で」に傍点］", false, "晩ステーションで"},
+		{"晩｜停車場《ていしゃば》", true, "晩テイシャバ"},
+		{"その※［＃「足へん＋宛」、第3水準1-92-36］き《もがき》", false, "その踠もがき"},
+		{"頭をフラ／＼させ、しげ／″＼と見る", false, "頭をフラフラさせ、しげじげと見る"},
+		{"昭和10［＃「10」は縦中横］年", false, "昭和10年"},
+	}
+	for _, tc := range tests {
+		actual, ok := newReadingEscaper(tc.katakana).Escape(tc.in)
+		if ok != true {
+			t.Errorf("always true")
+		}
+		if actual != tc.expect {
+			t.Errorf("%s: expect=%q actual=%q", tc.in, tc.expect, actual)
+		}
+	}
+}
+
+func TestConvRubyReading(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	in := "蚊帳《かや》の外／＼\r\n"
+	if err := Conv(out, strings.NewReader(in), WithRubyReadingKatakana(), WithoutRuby(), WithoutAnnotation()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if out.String() != "カヤの外の外\r\n" {
+		t.Errorf("actual=%q", out.String())
+	}
+}
@@ -0,0 +1,220 @@
+package aozoraconv
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// SearchMatch is a match of search, Start and End are byte offsets of the source text
+type SearchMatch struct {
+	Line      int    `json:"line"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Text      string `json:"text"`
+	ByReading bool   `json:"by_reading"`
+}
+
+// searchUnit is a character of plain text and the byte range of source,
+// ruby is the index of ruby if the character is in ruby base
+type searchUnit struct {
+	char       string
+	start, end int
+	ruby       int
+}
+
+type searchRuby struct {
+	reading     string
+	first, last int
+}
+
+type searchLine struct {
+	line   int
+	offset int64
+	src    string
+	units  []searchUnit
+	rubies []searchRuby
+}
+
+// SearchIndex is an index of body text without notation, texts are matched
+// by ruby bases or readings (かんじ matches 漢字《かんじ》)
+type SearchIndex struct {
+	lines []*searchLine
+}
+
+// NewSearchIndex reads Aozora Bunko format text (UTF-8) and returns index of body text
+func NewSearchIndex(r io.Reader) (*SearchIndex, error) {
+	srcs := make([]string, 0, 1024)
+	doc := &Document{Lines: make([]*Line, 0, 1024)}
+	scan := NewAozoraTextScanner(r)
+	for scan.Scan() {
+		srcs = append(srcs, scan.Text())
+		doc.Lines = append(doc.Lines, ParseLine(scan.Text()))
+	}
+	if err := scan.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	header, body, _ := doc.Split()
+	idx := &SearchIndex{lines: make([]*searchLine, 0, len(body))}
+	offset := int64(0)
+	for i, src := range srcs {
+		if len(header) <= i && i < len(header)+len(body) {
+			idx.lines = append(idx.lines, newSearchLine(i+1, offset, src, doc.Lines[i]))
+		}
+		offset += int64(len(src))
+	}
+	return idx, nil
+}
+
+func newSearchLine(line int, offset int64, src string, l *Line) *searchLine {
+	s := &searchLine{
+		line:   line,
+		offset: offset,
+		src:    strings.TrimSuffix(src, l.EOL),
+		units:  make([]searchUnit, 0, len(src)/3),
+		rubies: make([]searchRuby, 0, 4),
+	}
+	pos := 0
+	s.locate(l.Nodes, &pos, -1)
+	return s
+}
+
+// locate walks nodes along the source and records positions of characters
+func (s *searchLine) locate(nodes []Node, pos *int, ruby int) {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			*pos = s.seek(*pos, v.Value)
+			for _, r := range v.Value {
+				size := utf8.RuneLen(r)
+				s.units = append(s.units, searchUnit{char: string(r), start: *pos, end: *pos + size, ruby: ruby})
+				*pos += size
+			}
+		case *Ruby:
+			k := len(s.rubies)
+			s.rubies = append(s.rubies, searchRuby{reading: v.Reading})
+			if v.Explicit {
+				*pos = s.seek(*pos, notationRubyIndex) + len(notationRubyIndex)
+			}
+			s.rubies[k].first = len(s.units)
+			s.locate(v.Base, pos, k)
+			s.rubies[k].last = len(s.units)
+			notation := notationRubyOpen + v.Reading + notationRubyClose
+			*pos = s.seek(*pos, notation) + len(notation)
+		case *Gaiji:
+			start := s.seek(*pos, notationGaijiOpen)
+			*pos = s.skipNotation(start, notationGaijiOpen)
+			char := v.Char
+			if char == "" {
+				char = "※"
+			}
+			s.units = append(s.units, searchUnit{char: char, start: start, end: *pos, ruby: ruby})
+		case *Layout:
+			if v.Reference != true {
+				*pos = s.skipNotation(s.seek(*pos, notationAnnotationOpen), notationAnnotationOpen)
+			}
+			s.locate(v.Nodes, pos, ruby)
+			*pos = s.skipNotation(s.seek(*pos, notationAnnotationOpen), notationAnnotationOpen)
+		default:
+			*pos = s.skipNotation(s.seek(*pos, notationAnnotationOpen), notationAnnotationOpen)
+		}
+	}
+}
+
+// seek returns position of text at or after pos
+func (s *searchLine) seek(pos int, text string) int {
+	if i := strings.Index(s.src[pos:], text); 0 <= i {
+		return pos + i
+	}
+	return pos
+}
+
+// skipNotation returns position after notation (※［＃…］ or ［＃…］) at pos
+func (s *searchLine) skipNotation(pos int, open string) int {
+	if strings.HasPrefix(s.src[pos:], open) != true {
+		return pos
+	}
+	if _, n, ok := annotationBody(s.src[pos+len(open):]); ok {
+		return pos + len(open) + n
+	}
+	return pos
+}
+
+// match returns index of the next unit of the match which starts at unit i,
+// byReading is true if a ruby is matched by the reading
+func (s *searchLine) match(i int, query string) (end int, byReading bool, ok bool) {
+	if query == "" {
+		return i, false, true
+	}
+	if len(s.units) <= i {
+		return -1, false, false
+	}
+	u := s.units[i]
+	if 0 <= u.ruby && s.rubies[u.ruby].first == i {
+		if reading := s.rubies[u.ruby].reading; strings.HasPrefix(query, reading) {
+			if end, _, ok := s.match(s.rubies[u.ruby].last, query[len(reading):]); ok {
+				return end, true, true
+			}
+		}
+	}
+	if strings.HasPrefix(query, u.char) {
+		return s.match(i+1, query[len(u.char):])
+	}
+	return -1, false, false
+}
+
+func (s *searchLine) newMatch(first, last int, byReading bool) SearchMatch {
+	start, end := s.units[first].start, s.units[last-1].end
+	return SearchMatch{
+		Line:      s.line,
+		Start:     s.offset + int64(start),
+		End:       s.offset + int64(end),
+		Text:      s.src[start:end],
+		ByReading: byReading,
+	}
+}
+
+func (s *searchLine) search(query string) []SearchMatch {
+	matches := make([]SearchMatch, 0, 2)
+	for i := range s.units {
+		if end, byReading, ok := s.match(i, query); ok {
+			matches = append(matches, s.newMatch(i, end, byReading))
+		}
+	}
+	// query in a part of reading matches the whole ruby base
+	for _, r := range s.rubies {
+		if r.first < r.last && r.reading != query && strings.Contains(r.reading, query) {
+			matches = append(matches, s.newMatch(r.first, r.last, true))
+		}
+	}
+	return matches
+}
+
+// Search returns matches of query in order of position, a match is in a line
+func (idx *SearchIndex) Search(query string) []SearchMatch {
+	matches := make([]SearchMatch, 0, 8)
+	if query == "" {
+		return matches
+	}
+	for _, l := range idx.lines {
+		matches = append(matches, l.search(query)...)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End < matches[j].End
+	})
+	uniq := matches[:0]
+	for _, m := range matches {
+		if 0 < len(uniq) && m.Start == uniq[len(uniq)-1].Start && m.End == uniq[len(uniq)-1].End {
+			continue
+		}
+		uniq = append(uniq, m)
+	}
+	return uniq
+}
@@ -0,0 +1,60 @@
+package aozoraconv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	in := strings.Join([]string{
+		"題名",
+		"著者",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"［＃３字下げ］漢字《かんじ》の｜読み方《よみかた》",
+		"※［＃「木＋世」、U+67BB、13-3］と10［＃「10」は縦中横］の漢字",
+		"",
+		"底本：「漢字の本」",
+		"",
+	}, "\r\n")
+	idx, err := NewSearchIndex(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	type match struct {
+		line      int
+		text      string
+		byReading bool
+	}
+	tests := []struct {
+		query  string
+		expect []match
+	}{
+		{"漢字", []match{{9, "漢字", false}, {10, "漢字", false}}},
+		{"かんじの", []match{{9, "漢字《かんじ》の", true}}},
+		{"みか", []match{{9, "読み方", true}}},
+		{"読み方", []match{{9, "読み方", false}}},
+		{"枻と10の", []match{{10, "※［＃「木＋世」、U+67BB、13-3］と10［＃「10」は縦中横］の", false}}},
+		{"題名", []match{}},
+	}
+	for _, tc := range tests {
+		actual := idx.Search(tc.query)
+		if len(actual) != len(tc.expect) {
+			t.Errorf("%s: expect=%v actual=%v", tc.query, tc.expect, actual)
+			continue
+		}
+		for i, m := range actual {
+			e := tc.expect[i]
+			if m.Line != e.line || m.Text != e.text || m.ByReading != e.byReading {
+				t.Errorf("%s: expect=%v actual=%v", tc.query, e, m)
+			}
+			if in[m.Start:m.End] != m.Text {
+				t.Errorf("%s: offset %d-%d is %s", tc.query, m.Start, m.End, in[m.Start:m.End])
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package aozoraconv
+
+import (
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// ShiftJIS2004 is the Shift_JIS-2004 encoding (JIS X 0213), combining character
+// sequences (か + U+309A) are encoded into one character
+var ShiftJIS2004 encoding.Encoding = shiftJIS2004{}
+
+type shiftJIS2004 struct{}
+
+func (shiftJIS2004) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: new(sjis2004Decoder)}
+}
+
+func (shiftJIS2004) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: new(sjis2004Encoder)}
+}
+
+func (shiftJIS2004) String() string {
+	return "Shift_JIS-2004"
+}
+
+// sjis2004Rune converts characters of JIS X 0213 table into the full-width forms
+// (1-3-33 is "A" in the table but "Ａ" U+FF21 in Shift_JIS-2004)
+func sjis2004Rune(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) {
+		return s
+	}
+	switch {
+	case 0x21 <= r && r <= 0x7e:
+		return string(r + 0xfee0)
+	case r == 0x203e:
+		return "￣"
+	case r == 0xa5:
+		return "￥"
+	}
+	return s
+}
+
+// sjis2004Lookup returns JisEntry of the character, reverse of sjis2004Rune
+func sjis2004Lookup(r rune) (JisEntry, bool) {
+	switch {
+	case 0xff01 <= r && r <= 0xff5e:
+		r -= 0xfee0
+	case r == 0xffe3:
+		r = 0x203e
+	case r == 0xffe5:
+		r = 0xa5
+	}
+	if entry, ok := lookupJis(r); ok {
+		return entry, true
+	}
+	for _, eq := range canonicalEquivalents(r) {
+		if entry, ok := lookupJis(eq); ok {
+			return entry, true
+		}
+	}
+	return JisEntry{0, 0, 0}, false
+}
+
+type sjis2004Decoder struct {
+	transform.NopResetter
+}
+
+func (d *sjis2004Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c := src[nSrc]
+		var s string
+		size := 1
+		switch {
+		case c < utf8.RuneSelf:
+			s = string(rune(c))
+		case 0xa1 <= c && c <= 0xdf:
+			s = string(rune(c) - 0xa1 + 0xff61)
+		case (0x81 <= c && c <= 0x9f) || (0xe0 <= c && c <= 0xfc):
+			if len(src) <= nSrc+1 {
+				if atEOF != true {
+					return nDst, nSrc, transform.ErrShortSrc
+				}
+				s = string(utf8.RuneError)
+				break
+			}
+			if c2 := src[nSrc+1]; 0x40 <= c2 && c2 <= 0xfc && c2 != 0x7f {
+				size = 2
+			}
+			s = string(utf8.RuneError)
+			if men, ku, ten, e := Sjis2Kuten(src[nSrc : nSrc+2]); e == nil {
+				if chr, e := Jis2Uni(men, ku, ten); e == nil {
+					s = sjis2004Rune(chr)
+				}
+			}
+		default:
+			s = string(utf8.RuneError)
+		}
+		if len(dst) < nDst+len(s) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], s)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+type sjis2004Encoder struct {
+	transform.NopResetter
+}
+
+func (e *sjis2004Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := rune(src[nSrc]), 1
+		if utf8.RuneSelf <= r {
+			if atEOF != true && utf8.FullRune(src[nSrc:]) != true {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+			if r == utf8.RuneError && size == 1 {
+				return nDst, nSrc, errors.Errorf("invalid UTF-8 at %d", nSrc)
+			}
+		}
+
+		var b []byte
+		switch {
+		case r < utf8.RuneSelf:
+			b = []byte{byte(r)}
+		case 0xff61 <= r && r <= 0xff9f:
+			b = []byte{byte(r - 0xff61 + 0xa1)}
+		default:
+			entry, ok := JisEntry{0, 0, 0}, false
+			if pairs, has := multichars[r]; has {
+				rest := src[nSrc+size:]
+				if atEOF != true && (len(rest) < 1 || utf8.FullRune(rest) != true) {
+					return nDst, nSrc, transform.ErrShortSrc
+				}
+				// greedy match of combining character sequence
+				if r2, n := utf8.DecodeRune(rest); 0 < n {
+					if entry, ok = pairs[r2]; ok {
+						size += n
+					}
+				}
+			}
+			if ok != true {
+				entry, ok = sjis2004Lookup(r)
+			}
+			if ok != true {
+				return nDst, nSrc, errors.Errorf("%U is not in Shift_JIS-2004", r)
+			}
+			sjis, err := entry.ShiftJIS2004Bytes()
+			if err != nil {
+				return nDst, nSrc, errors.WithStack(err)
+			}
+			b = sjis
+		}
+		if len(dst) < nDst+len(b) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], b)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
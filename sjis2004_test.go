@@ -0,0 +1,102 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShiftJIS2004Multichars(t *testing.T) {
+	for r1, pairs := range multichars {
+		for r2, entry := range pairs {
+			s := string([]rune{r1, r2})
+			expect, err := entry.ShiftJIS2004Bytes()
+			if err != nil {
+				t.Fatalf("%v: no error: %+v", entry, err)
+			}
+			actual, err := ShiftJIS2004.NewEncoder().Bytes([]byte(s))
+			if err != nil {
+				t.Errorf("%+q: no error: %+v", s, err)
+				continue
+			}
+			if bytes.Equal(expect, actual) != true {
+				t.Errorf("%+q: expect=%X actual=%X", s, expect, actual)
+			}
+			decoded, err := ShiftJIS2004.NewDecoder().String(string(actual))
+			if err != nil {
+				t.Errorf("%X: no error: %+v", actual, err)
+			}
+			if decoded != s {
+				t.Errorf("%X: expect=%+q actual=%+q", actual, s, decoded)
+			}
+		}
+	}
+}
+
+func TestShiftJIS2004RoundTrip(t *testing.T) {
+	for men := 1; men <= 2; men += 1 {
+		for ku := 1; ku <= 94; ku += 1 {
+			for ten := 1; ten <= 94; ten += 1 {
+				if _, err := Jis2Uni(men, ku, ten); err != nil {
+					continue
+				}
+				b, err := MenKuTen2Sjis2004(men, ku, ten)
+				if err != nil {
+					continue
+				}
+				s, err := ShiftJIS2004.NewDecoder().Bytes(b)
+				if err != nil {
+					t.Errorf("%d-%d-%d: no error: %+v", men, ku, ten, err)
+					continue
+				}
+				actual, err := ShiftJIS2004.NewEncoder().Bytes(s)
+				if err != nil {
+					t.Errorf("%d-%d-%d %+q: no error: %+v", men, ku, ten, s, err)
+					continue
+				}
+				if bytes.Equal(b, actual) != true {
+					t.Errorf("%d-%d-%d %+q: expect=%X actual=%X", men, ku, ten, s, b, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestShiftJIS2004(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect []byte
+	}{
+		{"か゚き", []byte{0x82, 0xf5, 0x82, 0xab}},
+		{"かき", []byte{0x82, 0xa9, 0x82, 0xab}},
+		{"Ａa〜ｱ", []byte{0x82, 0x60, 0x61, 0x81, 0x60, 0xb1}},
+	}
+	for _, tc := range tests {
+		actual, err := ShiftJIS2004.NewEncoder().String(tc.in)
+		if err != nil {
+			t.Errorf("%s: no error: %+v", tc.in, err)
+			continue
+		}
+		if actual != string(tc.expect) {
+			t.Errorf("%s: expect=%X actual=%X", tc.in, tc.expect, actual)
+		}
+	}
+	if _, err := ShiftJIS2004.NewEncoder().String("☺"); err == nil {
+		t.Errorf("not in Shift_JIS-2004")
+	}
+}
+
+func TestEncode2004(t *testing.T) {
+	in := "か゚〜塚︀\r\n"
+	sjis := bytes.NewBuffer(nil)
+	if err := Encode2004(sjis, strings.NewReader(in)); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := Decode2004(out, sjis); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if expect := "か゚〜\uFA10\r\n"; out.String() != expect {
+		t.Errorf("expect=%+q actual=%+q", expect, out.String())
+	}
+}
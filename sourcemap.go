@@ -0,0 +1,251 @@
+package aozoraconv
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// sourceMapMaxCells is the maximum size of table to align a line by the longest
+// common subsequence, longer lines are aligned character by character
+const sourceMapMaxCells = 1 << 20
+
+// SourcePosition is a position of the source text, Line is 1-based,
+// Col is byte offset in the line and Offset is byte offset from the start of the source
+type SourcePosition struct {
+	Line   int   `json:"line"`
+	Col    int   `json:"col"`
+	Offset int64 `json:"offset"`
+}
+
+// SourceMap maps rune offsets of converted text into positions of the source text
+// (UTF-8 text read by escapers), it is recorded by WithSourceMap.
+// characters are aligned by line, characters replaced by escapers (e.g. resolved gaiji)
+// are mapped to the start of the replaced notation
+type SourceMap struct {
+	offsets    []int64
+	lineStarts []int64
+}
+
+// NewSourceMap returns empty SourceMap, use a SourceMap per conversion
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		offsets:    make([]int64, 0, 4*1024),
+		lineStarts: make([]int64, 0, 256),
+	}
+}
+
+// Len returns the number of runes of converted text
+func (m *SourceMap) Len() int {
+	return len(m.offsets)
+}
+
+// Lookup returns source position of rune at offset in converted text
+func (m *SourceMap) Lookup(runeOffset int) (SourcePosition, bool) {
+	if runeOffset < 0 || len(m.offsets) <= runeOffset {
+		return SourcePosition{}, false
+	}
+	offset := m.offsets[runeOffset]
+	i := sort.Search(len(m.lineStarts), func(i int) bool {
+		return offset < m.lineStarts[i]
+	}) - 1
+	if i < 0 {
+		return SourcePosition{Line: 0, Col: int(offset), Offset: offset}, true
+	}
+	return SourcePosition{Line: i + 1, Col: int(offset - m.lineStarts[i]), Offset: offset}, true
+}
+
+// addLine records line of the source which starts at offset, source is the line
+// replaced rune by rune from original and escaped is the output of escapers for source
+// (empty if the line is removed)
+func (m *SourceMap) addLine(offset int64, original, source, escaped string) {
+	m.lineStarts = append(m.lineStarts, offset)
+	if escaped == "" {
+		return
+	}
+
+	byteOffsets := make([]int, 0, len(original)+1)
+	for i := range original {
+		byteOffsets = append(byteOffsets, i)
+	}
+	byteOffsets = append(byteOffsets, len(original))
+
+	in := []rune(source)
+	for _, k := range alignRunes([]rune(escaped), in) {
+		if len(byteOffsets) <= k {
+			k = len(byteOffsets) - 1
+		}
+		m.offsets = append(m.offsets, offset+int64(byteOffsets[k]))
+	}
+}
+
+// notationSpans returns end index of notation (［＃…］, ※［＃…］, 《…》, ｜, ／＼ and ／″＼)
+// which starts at the index of runes
+func notationSpans(in []rune) map[int]int {
+	spans := make(map[int]int, 8)
+	s := string(in)
+	index := 0
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		n := 0
+		switch {
+		case strings.HasPrefix(rest, notationGaijiOpen):
+			if _, size, ok := annotationBody(rest[len(notationGaijiOpen):]); ok {
+				n = len(notationGaijiOpen) + size
+			}
+		case strings.HasPrefix(rest, notationAnnotationOpen):
+			if _, size, ok := annotationBody(rest[len(notationAnnotationOpen):]); ok {
+				n = len(notationAnnotationOpen) + size
+			}
+		case strings.HasPrefix(rest, notationRubyOpen):
+			if size := strings.Index(rest, notationRubyClose); 0 < size {
+				n = size + len(notationRubyClose)
+			}
+		case strings.HasPrefix(rest, notationRubyIndex):
+			n = len(notationRubyIndex)
+		case strings.HasPrefix(rest, "／＼"):
+			n = len("／＼")
+		case strings.HasPrefix(rest, "／″＼"):
+			n = len("／″＼")
+		}
+		if 0 < n {
+			count := utf8.RuneCountInString(rest[:n])
+			spans[index] = index + count
+			index += count
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		index += 1
+		i += size
+	}
+	return spans
+}
+
+// alignRunes returns index of in for each rune of out, out is in derived by
+// removing notation or replacing characters. runes are aligned by the longest common
+// subsequence, a rune of out which is not in it is mapped to the unmatched rune of in
+// (the start of replaced notation or the replaced character)
+func alignRunes(out, in []rune) []int {
+	indexes := make([]int, 0, len(out))
+	prefix := 0
+	for prefix < len(out) && prefix < len(in) && out[prefix] == in[prefix] {
+		indexes = append(indexes, prefix)
+		prefix += 1
+	}
+	suffix := 0
+	for suffix < len(out)-prefix && suffix < len(in)-prefix && out[len(out)-1-suffix] == in[len(in)-1-suffix] {
+		suffix += 1
+	}
+
+	o, n := out[prefix:len(out)-suffix], in[prefix:len(in)-suffix]
+	if (len(o)+1)*(len(n)+1) <= sourceMapMaxCells {
+		indexes = alignLCS(indexes, o, n, prefix)
+	} else {
+		indexes = alignSequential(indexes, o, n, prefix)
+	}
+	for k := len(in) - suffix; k < len(in); k += 1 {
+		indexes = append(indexes, k)
+	}
+	return indexes
+}
+
+// alignLCS appends index of n (+ offset) for each rune of o, runes which are not
+// in the longest common subsequence are replacements of unmatched runes of n
+func alignLCS(indexes []int, o, n []rune, offset int) []int {
+	cols := len(n) + 1
+	lcs := make([]int32, (len(o)+1)*cols)
+	for i := len(o) - 1; 0 <= i; i -= 1 {
+		for j := len(n) - 1; 0 <= j; j -= 1 {
+			switch {
+			case o[i] == n[j]:
+				lcs[i*cols+j] = lcs[(i+1)*cols+j+1] + 1
+			case lcs[i*cols+j+1] < lcs[(i+1)*cols+j]:
+				lcs[i*cols+j] = lcs[(i+1)*cols+j]
+			default:
+				lcs[i*cols+j] = lcs[i*cols+j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(o))
+	i, j := 0, 0
+	for i < len(o) {
+		switch {
+		case j < len(n) && o[i] == n[j]:
+			matches[i] = j
+			i += 1
+			j += 1
+		case j < len(n) && lcs[(i+1)*cols+j] <= lcs[i*cols+j+1]:
+			j += 1
+		default:
+			matches[i] = -1
+			i += 1
+		}
+	}
+
+	spans := notationSpans(n)
+	gap := 0
+	for i := 0; i < len(o); {
+		if 0 <= matches[i] {
+			indexes = append(indexes, offset+matches[i])
+			gap = matches[i] + 1
+			i += 1
+			continue
+		}
+		// unmatched runes of o replace runes (or notation) of n between matches one by one
+		end := i
+		for end < len(o) && matches[end] < 0 {
+			end += 1
+		}
+		gapEnd := len(n)
+		if end < len(o) {
+			gapEnd = matches[end]
+		}
+		k, last := gap, gap
+		for ; i < end; i += 1 {
+			if k < gapEnd {
+				last = k
+				if spanEnd, isSpan := spans[k]; isSpan {
+					k = spanEnd
+				} else {
+					k += 1
+				}
+			}
+			indexes = append(indexes, offset+last)
+		}
+	}
+	return indexes
+}
+
+// alignSequential appends index of n (+ offset) for each rune of o, notation of n
+// which is not in o is skipped and a rune which is not found is a replacement of the rune of n
+func alignSequential(indexes []int, o, n []rune, offset int) []int {
+	spans := notationSpans(n)
+	j := 0
+	for _, r := range o {
+		k := j
+		for k < len(n) && n[k] != r {
+			end, isSpan := spans[k]
+			if isSpan != true {
+				break
+			}
+			k = end
+		}
+		switch {
+		case k < len(n) && n[k] == r:
+			indexes = append(indexes, offset+k)
+			j = k + 1
+		case j < len(n):
+			indexes = append(indexes, offset+j)
+			if end, isSpan := spans[j]; isSpan {
+				j = end
+			} else {
+				j += 1
+			}
+		default:
+			indexes = append(indexes, offset+len(n))
+		}
+	}
+	return indexes
+}
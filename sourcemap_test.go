@@ -0,0 +1,128 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvSourceMap(t *testing.T) {
+	in := strings.Join([]string{
+		"漢字《かんじ》の¢［＃「の」に傍点］",
+		"※［＃「木＋世」、U+67BB、13-3］フラ／＼と",
+		"",
+	}, "\r\n")
+	m := NewSourceMap()
+	out := bytes.NewBuffer(nil)
+	err := Conv(out, strings.NewReader(in), WithoutRuby(), WithoutAnnotation(), WithResolveGaiji(), WithoutRepeatTwo(), WithSourceMap(m))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	output := []rune(out.String())
+	if m.Len() != len(output) {
+		t.Fatalf("expect=%d actual=%d", len(output), m.Len())
+	}
+
+	line2 := strings.Index(in, "※")
+	repeat := strings.Index(in, "フラ") - line2
+	tests := []struct {
+		char   string
+		index  int
+		expect SourcePosition
+	}{
+		{"漢", 0, SourcePosition{Line: 1, Col: 0, Offset: 0}},
+		{"の", 2, SourcePosition{Line: 1, Col: 21, Offset: 21}},
+		{"￠", 3, SourcePosition{Line: 1, Col: 24, Offset: 24}},
+		{"\r", 4, SourcePosition{Line: 1, Col: 53, Offset: 53}},
+		{"枻", 6, SourcePosition{Line: 2, Col: 0, Offset: int64(line2)}},
+		{"フ", 7, SourcePosition{Line: 2, Col: repeat, Offset: int64(line2 + repeat)}},
+		{"フ", 9, SourcePosition{Line: 2, Col: repeat + 6, Offset: int64(line2 + repeat + 6)}},
+		{"ラ", 10, SourcePosition{Line: 2, Col: repeat + 6, Offset: int64(line2 + repeat + 6)}},
+		{"と", 11, SourcePosition{Line: 2, Col: repeat + 12, Offset: int64(line2 + repeat + 12)}},
+	}
+	for _, tc := range tests {
+		if string(output[tc.index]) != tc.char {
+			t.Errorf("%d: expect=%s actual=%s", tc.index, tc.char, string(output[tc.index]))
+		}
+		actual, ok := m.Lookup(tc.index)
+		if ok != true {
+			t.Errorf("%d: must be found", tc.index)
+		}
+		if actual != tc.expect {
+			t.Errorf("%s %d: expect=%+v actual=%+v", tc.char, tc.index, tc.expect, actual)
+		}
+	}
+	if _, ok := m.Lookup(len(output)); ok {
+		t.Errorf("out of range")
+	}
+}
+
+func TestConvSourceMapReplace(t *testing.T) {
+	in := strings.Join([]string{
+		"學校と学校ゐるいぬABC",
+		"※［＃「木＋世」、U+67BB、13-3］と",
+		"",
+	}, "\r\n")
+	m := NewSourceMap()
+	out := bytes.NewBuffer(nil)
+	err := Conv(out, strings.NewReader(in), WithModernize(), WithFullWidth(), WithResolveGaiji(), WithSourceMap(m))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := "学校と学校いるいぬＡＢＣ\r\n枻と\r\n"
+	if out.String() != expect {
+		t.Fatalf("expect=%s actual=%s", expect, out.String())
+	}
+
+	// characters are replaced one by one in the first line
+	line1 := []rune(strings.SplitAfter(in, "\r\n")[0])
+	offset := 0
+	for i, r := range line1 {
+		actual, ok := m.Lookup(i)
+		if ok != true {
+			t.Fatalf("%d: must be found", i)
+		}
+		if actual != (SourcePosition{Line: 1, Col: offset, Offset: int64(offset)}) {
+			t.Errorf("%d %s: expect=%d actual=%+v", i, string(r), offset, actual)
+		}
+		offset += len(string(r))
+	}
+
+	line2 := int64(strings.Index(in, "※"))
+	tests := []struct {
+		index  int
+		expect int64
+	}{
+		{len(line1), line2},
+		{len(line1) + 1, int64(strings.Index(in, "と\r\n"))},
+		{len(line1) + 2, int64(strings.Index(in, "と\r\n") + len("と"))},
+	}
+	for _, tc := range tests {
+		actual, ok := m.Lookup(tc.index)
+		if ok != true {
+			t.Fatalf("%d: must be found", tc.index)
+		}
+		if actual.Line != 2 || actual.Offset != tc.expect {
+			t.Errorf("%d: expect=%d actual=%+v", tc.index, tc.expect, actual)
+		}
+	}
+}
+
+func TestAlignRunes(t *testing.T) {
+	tests := []struct {
+		out, in string
+		expect  []int
+	}{
+		{"学校と学校", "學校と学校", []int{0, 1, 2, 3, 4}},
+		{"いるいぬ", "ゐるいぬ", []int{0, 1, 2, 3}},
+		{"葛飾", "葛\U000E0101飾", []int{0, 2}},
+		{"フラフラと", "フラ／＼と", []int{0, 1, 2, 2, 4}},
+	}
+	for _, tc := range tests {
+		actual := alignRunes([]rune(tc.out), []rune(tc.in))
+		if reflect.DeepEqual(tc.expect, actual) != true {
+			t.Errorf("%s: expect=%v actual=%v", tc.out, tc.expect, actual)
+		}
+	}
+}
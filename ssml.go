@@ -0,0 +1,256 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const emphasisKind = "傍点"
+
+var (
+	headingAnnotation           = regexp.MustCompile(`見出し`)
+	emphasisReferenceAnnotation = regexp.MustCompile(`^「(.+)」に[^「」]*傍点$`)
+	emphasisStartAnnotation     = regexp.MustCompile(`^[^「」]*傍点$`)
+	emphasisEndAnnotation       = regexp.MustCompile(`^[^「」]*傍点終わり$`)
+)
+
+type SSMLOptionFunc func(*ssmlOption)
+
+type ssmlOption struct {
+	Lang            string
+	PhonemeAlphabet string
+	HeadingBreak    time.Duration
+	PageBreak       time.Duration
+}
+
+// WithSSMLLang sets xml:lang of speak element (default: ja-JP)
+func WithSSMLLang(lang string) SSMLOptionFunc {
+	return func(opt *ssmlOption) {
+		opt.Lang = lang
+	}
+}
+
+// WithSSMLPhoneme renders ruby as <phoneme alphabet="alphabet" ph="reading">
+// instead of <sub alias="reading">
+func WithSSMLPhoneme(alphabet string) SSMLOptionFunc {
+	return func(opt *ssmlOption) {
+		opt.PhonemeAlphabet = alphabet
+	}
+}
+
+// WithSSMLHeadingBreak sets duration of break around headings (default: 1s)
+func WithSSMLHeadingBreak(d time.Duration) SSMLOptionFunc {
+	return func(opt *ssmlOption) {
+		opt.HeadingBreak = d
+	}
+}
+
+// WithSSMLPageBreak sets duration of break at page breaks (default: 2s)
+func WithSSMLPageBreak(d time.Duration) SSMLOptionFunc {
+	return func(opt *ssmlOption) {
+		opt.PageBreak = d
+	}
+}
+
+func newSSMLOption(funcs ...SSMLOptionFunc) *ssmlOption {
+	opt := &ssmlOption{
+		Lang:            "ja-JP",
+		PhonemeAlphabet: "",
+		HeadingBreak:    1 * time.Second,
+		PageBreak:       2 * time.Second,
+	}
+	for _, fn := range funcs {
+		fn(opt)
+	}
+	return opt
+}
+
+// RenderSSML renders document as SSML for speech synthesis, ruby readings are
+// used as pronunciation, header (except title and author) and footer are not rendered
+func RenderSSML(w io.Writer, doc *Document, opts ...SSMLOptionFunc) error {
+	opt := newSSMLOption(opts...)
+
+	buf := &ssmlBuffer{Buffer: bytes.NewBuffer(make([]byte, 0, 4*1024))}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(buf, `<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="%s">`+"\n", html.EscapeString(opt.Lang))
+
+	header, body, _ := doc.Split()
+	if 0 < len(header) {
+		title, author := doc.Title()
+		for _, s := range []string{title, author} {
+			if s != "" {
+				buf.writeParagraph(html.EscapeString(s))
+			}
+		}
+		buf.writeBreak(opt.HeadingBreak)
+	}
+	for _, l := range body {
+		writeSSMLLine(buf, l, opt)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return errors.WithStack(err)
+		}
+		buf.Reset()
+	}
+	buf.flushBreak()
+	buf.WriteString("</speak>\n")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ssmlBuffer collapses adjacent breaks into the longest one
+type ssmlBuffer struct {
+	*bytes.Buffer
+	pendingBreak time.Duration
+}
+
+func (b *ssmlBuffer) writeBreak(d time.Duration) {
+	if b.pendingBreak < d {
+		b.pendingBreak = d
+	}
+}
+
+func (b *ssmlBuffer) flushBreak() {
+	if b.pendingBreak <= 0 {
+		return
+	}
+	fmt.Fprintf(b, `<break time="%dms"/>`+"\n", b.pendingBreak.Milliseconds())
+	b.pendingBreak = 0
+}
+
+func (b *ssmlBuffer) writeParagraph(p string) {
+	b.flushBreak()
+	fmt.Fprintf(b, "<p>%s</p>\n", p)
+}
+
+func writeSSMLLine(buf *ssmlBuffer, l *Line, opt *ssmlOption) {
+	heading := false
+	hasKanbun := false
+	for _, n := range l.Nodes {
+		switch v := n.(type) {
+		case *Annotation:
+			if headingAnnotation.MatchString(v.Body) {
+				heading = true
+			}
+		case *Kanbun:
+			hasKanbun = true
+		}
+	}
+
+	p := bytes.NewBuffer(make([]byte, 0, 256))
+	if hasKanbun {
+		p.WriteString(html.EscapeString(Kakikudashi(l.Nodes)))
+	} else {
+		for _, seg := range splitPageBreak(emphasize(l.Nodes)) {
+			if seg.Break != nil {
+				writeSSMLParagraph(buf, p, heading, opt)
+				buf.writeBreak(opt.PageBreak)
+				continue
+			}
+			writeSSMLNodes(p, seg.Nodes, opt)
+		}
+	}
+	writeSSMLParagraph(buf, p, heading, opt)
+}
+
+// writeSSMLParagraph writes p as paragraph and resets p, headings have breaks around it
+func writeSSMLParagraph(buf *ssmlBuffer, p *bytes.Buffer, heading bool, opt *ssmlOption) {
+	if strings.TrimSpace(strings.Trim(p.String(), "　")) == "" {
+		p.Reset()
+		return
+	}
+	if heading {
+		buf.writeBreak(opt.HeadingBreak)
+	}
+	buf.writeParagraph(p.String())
+	if heading {
+		buf.writeBreak(opt.HeadingBreak)
+	}
+	p.Reset()
+}
+
+func writeSSMLNodes(buf *bytes.Buffer, nodes []Node, opt *ssmlOption) {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			buf.WriteString(html.EscapeString(expandRepeatMarks(v.Value)))
+		case *Ruby:
+			if opt.PhonemeAlphabet != "" {
+				fmt.Fprintf(buf, `<phoneme alphabet="%s" ph="%s">`, html.EscapeString(opt.PhonemeAlphabet), html.EscapeString(v.Reading))
+				buf.WriteString(html.EscapeString(PlainText(v.Base)))
+				buf.WriteString("</phoneme>")
+			} else {
+				fmt.Fprintf(buf, `<sub alias="%s">`, html.EscapeString(v.Reading))
+				buf.WriteString(html.EscapeString(PlainText(v.Base)))
+				buf.WriteString("</sub>")
+			}
+		case *Gaiji:
+			buf.WriteString(html.EscapeString(v.Char))
+		case *Layout:
+			if v.Kind == emphasisKind {
+				buf.WriteString("<emphasis>")
+				writeSSMLNodes(buf, v.Nodes, opt)
+				buf.WriteString("</emphasis>")
+			} else {
+				writeSSMLNodes(buf, v.Nodes, opt)
+			}
+		}
+	}
+}
+
+type pageBreakSegment struct {
+	Break *PageBreak
+	Nodes []Node
+}
+
+// splitPageBreak splits nodes at page breaks
+func splitPageBreak(nodes []Node) []pageBreakSegment {
+	segments := make([]pageBreakSegment, 0, 1)
+	last := 0
+	for i, n := range nodes {
+		if pb, ok := n.(*PageBreak); ok {
+			segments = append(segments, pageBreakSegment{Nodes: nodes[last:i]})
+			segments = append(segments, pageBreakSegment{Break: pb})
+			last = i + 1
+		}
+	}
+	return append(segments, pageBreakSegment{Nodes: nodes[last:]})
+}
+
+// emphasize replaces emphasis annotations (［＃「…」に傍点］ and ［＃傍点］…［＃傍点終わり］)
+// with Layout of emphasisKind
+func emphasize(nodes []Node) []Node {
+	out := make([]Node, 0, len(nodes))
+	start := -1
+	for _, n := range nodes {
+		a, ok := n.(*Annotation)
+		if ok != true {
+			out = append(out, n)
+			continue
+		}
+		switch {
+		case emphasisReferenceAnnotation.MatchString(a.Body):
+			target := emphasisReferenceAnnotation.FindStringSubmatch(a.Body)[1]
+			if rest, matched, found := splitTrailing(out, target); found {
+				out = append(rest, &Layout{Kind: emphasisKind, Nodes: matched, Reference: true})
+			}
+		case emphasisStartAnnotation.MatchString(a.Body):
+			start = len(out)
+		case emphasisEndAnnotation.MatchString(a.Body):
+			if 0 <= start {
+				children := append([]Node(nil), out[start:]...)
+				out = append(out[:start], &Layout{Kind: emphasisKind, Nodes: children})
+				start = -1
+			}
+		}
+	}
+	return out
+}
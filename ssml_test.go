@@ -0,0 +1,90 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSSML(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"［＃７字下げ］一［＃「一」は中見出し］",
+		"晩｜停車場《ステーション》で待つ［＃「待つ」に傍点］",
+		"［＃傍点］頭をフラ／＼［＃傍点終わり］と<振る>",
+		"［＃改ページ］",
+		"",
+		"底本：「真山青果全集」",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+
+	t.Run("sub", func(tt *testing.T) {
+		out := bytes.NewBuffer(nil)
+		if err := RenderSSML(out, doc, WithSSMLPageBreak(3*time.Second)); err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		expect := `<?xml version="1.0" encoding="UTF-8"?>
+<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="ja-JP">
+<p>茗荷畠</p>
+<p>眞山青果</p>
+<break time="1000ms"/>
+<p>一</p>
+<break time="1000ms"/>
+<p>晩<sub alias="ステーション">停車場</sub>で<emphasis>待つ</emphasis></p>
+<p><emphasis>頭をフラフラ</emphasis>と&lt;振る&gt;</p>
+<break time="3000ms"/>
+</speak>
+`
+		if out.String() != expect {
+			tt.Errorf("expect=%s\nactual=%s", expect, out.String())
+		}
+	})
+	t.Run("phoneme", func(tt *testing.T) {
+		out := bytes.NewBuffer(nil)
+		if err := RenderSSML(out, doc, WithSSMLPhoneme("x-JEITA"), WithSSMLHeadingBreak(0)); err != nil {
+			tt.Fatalf("no error: %+v", err)
+		}
+		expect := `<p>晩<phoneme alphabet="x-JEITA" ph="ステーション">停車場</phoneme>で<emphasis>待つ</emphasis></p>`
+		if strings.Contains(out.String(), expect) != true {
+			tt.Errorf("expect contains %s\nactual=%s", expect, out.String())
+		}
+		if strings.Contains(out.String(), `<break time="1000ms"/>`) {
+			tt.Errorf("no heading break: %s", out.String())
+		}
+	})
+}
+
+func TestRenderSSMLAdjacentBreaks(t *testing.T) {
+	doc, err := Parse(strings.NewReader("前［＃改ページ］\r\n一［＃「一」は中見出し］\r\n二［＃「二」は中見出し］\r\n"))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := RenderSSML(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="ja-JP">
+<p>前</p>
+<break time="2000ms"/>
+<p>一</p>
+<break time="1000ms"/>
+<p>二</p>
+<break time="1000ms"/>
+</speak>
+`
+	if out.String() != expect {
+		t.Errorf("expect=%s\nactual=%s", expect, out.String())
+	}
+}
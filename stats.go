@@ -0,0 +1,187 @@
+package aozoraconv
+
+import (
+	"sort"
+	"unicode"
+)
+
+type StatsOptionFunc func(*statsOption)
+
+type statsOption struct {
+	TopN         int
+	ReadingSpeed int
+}
+
+// WithStatsTopN sets number of the most frequent characters (default: 10)
+func WithStatsTopN(n int) StatsOptionFunc {
+	return func(opt *statsOption) {
+		opt.TopN = n
+	}
+}
+
+// WithStatsReadingSpeed sets characters per minute to estimate reading time (default: 500)
+func WithStatsReadingSpeed(charsPerMinute int) StatsOptionFunc {
+	return func(opt *statsOption) {
+		opt.ReadingSpeed = charsPerMinute
+	}
+}
+
+func newStatsOption(funcs ...StatsOptionFunc) *statsOption {
+	opt := &statsOption{
+		TopN:         10,
+		ReadingSpeed: 500,
+	}
+	for _, fn := range funcs {
+		fn(opt)
+	}
+	return opt
+}
+
+// CharCount is a character and the number of occurrences
+type CharCount struct {
+	Char  string `json:"char"`
+	Count int    `json:"count"`
+}
+
+// TextStats is character statistics of body text (header, footer and notation are excluded),
+// JISLevel1-4 are the number of kanji by JIS X 0213 level
+type TextStats struct {
+	Title          string      `json:"title"`
+	Author         string      `json:"author"`
+	Lines          int         `json:"lines"`
+	Chars          int         `json:"chars"`
+	Kanji          int         `json:"kanji"`
+	Hiragana       int         `json:"hiragana"`
+	Katakana       int         `json:"katakana"`
+	JISLevel1      int         `json:"jis_level1"`
+	JISLevel2      int         `json:"jis_level2"`
+	JISLevel3      int         `json:"jis_level3"`
+	JISLevel4      int         `json:"jis_level4"`
+	NonJIS         int         `json:"non_jis"`
+	Gaiji          int         `json:"gaiji"`
+	Ruby           int         `json:"ruby"`
+	RubyChars      int         `json:"ruby_chars"`
+	RubyDensity    float64     `json:"ruby_density"`
+	KanjiRatio     float64     `json:"kanji_ratio"`
+	ReadingMinutes float64     `json:"reading_minutes"`
+	TopChars       []CharCount `json:"top_chars"`
+}
+
+// Stats returns character statistics of body text, white spaces are not counted as characters
+func Stats(doc *Document, opts ...StatsOptionFunc) *TextStats {
+	opt := newStatsOption(opts...)
+	_, body, _ := doc.Split()
+
+	s := &TextStats{Lines: len(body), TopChars: []CharCount{}}
+	s.Title, s.Author = doc.Title()
+	counts := make(map[rune]int, 1024)
+	for _, l := range body {
+		s.countNodes(l.Nodes, counts)
+	}
+	for r, n := range counts {
+		s.countChar(r, n)
+	}
+
+	if 0 < s.Chars {
+		s.RubyDensity = float64(s.RubyChars) / float64(s.Chars)
+		s.KanjiRatio = float64(s.Kanji) / float64(s.Chars)
+	}
+	if 0 < opt.ReadingSpeed {
+		s.ReadingMinutes = float64(s.Chars) / float64(opt.ReadingSpeed)
+	}
+	s.TopChars = topChars(counts, opt.TopN)
+	return s
+}
+
+// countNodes counts characters of nodes, returns the number of counted characters
+func (s *TextStats) countNodes(nodes []Node, counts map[rune]int) int {
+	n := 0
+	for _, node := range nodes {
+		switch v := node.(type) {
+		case *Text:
+			n += countRunes(v.Value, counts)
+		case *Ruby:
+			s.Ruby += 1
+			c := s.countNodes(v.Base, counts)
+			s.RubyChars += c
+			n += c
+		case *Gaiji:
+			s.Gaiji += 1
+			if v.Char == "" {
+				// unresolved gaiji is a character which is not in counts
+				s.Chars += 1
+				n += 1
+				continue
+			}
+			n += countRunes(v.Char, counts)
+		case *Layout:
+			n += s.countNodes(v.Nodes, counts)
+		}
+	}
+	return n
+}
+
+func (s *TextStats) countChar(r rune, n int) {
+	s.Chars += n
+	switch {
+	case unicode.Is(unicode.Han, r):
+		s.Kanji += n
+	case unicode.Is(unicode.Hiragana, r):
+		s.Hiragana += n
+	case unicode.Is(unicode.Katakana, r):
+		s.Katakana += n
+	}
+
+	entry, ok := sjis2004Lookup(r)
+	if ok != true {
+		if r < 0x80 {
+			return
+		}
+		s.NonJIS += n
+		return
+	}
+	if unicode.Is(unicode.Han, r) != true {
+		return
+	}
+	switch entry.Level() {
+	case 1:
+		s.JISLevel1 += n
+	case 2:
+		s.JISLevel2 += n
+	case 3:
+		s.JISLevel3 += n
+	case 4:
+		s.JISLevel4 += n
+	}
+}
+
+// countRunes counts characters except white spaces, returns the number of counted characters
+func countRunes(text string, counts map[rune]int) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Mn, r) || isVariationSelector(r) {
+			continue
+		}
+		counts[r] += 1
+		n += 1
+	}
+	return n
+}
+
+// topChars returns n most frequent characters, ties are ordered by code point
+func topChars(counts map[rune]int, n int) []CharCount {
+	top := make([]CharCount, 0, len(counts))
+	for r, c := range counts {
+		top = append(top, CharCount{Char: string(r), Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[j].Count < top[i].Count
+		}
+		return top[i].Char < top[j].Char
+	})
+	if 0 <= n && n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
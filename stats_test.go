@@ -0,0 +1,62 @@
+package aozoraconv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	in := strings.Join([]string{
+		"茗荷畠",
+		"眞山青果",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ",
+		"-------------------------------------------------------",
+		"［＃３字下げ］晩｜停車場《ステーション》で待つ",
+		"※［＃「木＋世」、U+67BB、13-3］の葉　ＡＢ",
+		"",
+		"底本：「真山青果全集」",
+	}, "\r\n")
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	s := Stats(doc, WithStatsTopN(2), WithStatsReadingSpeed(6))
+
+	tests := []struct {
+		name          string
+		expect, value int
+	}{
+		{"lines", 3, s.Lines},
+		{"chars", 12, s.Chars},
+		{"kanji", 7, s.Kanji},
+		{"hiragana", 3, s.Hiragana},
+		{"katakana", 0, s.Katakana},
+		{"jis_level1", 6, s.JISLevel1},
+		{"jis_level3", 1, s.JISLevel3},
+		{"non_jis", 0, s.NonJIS},
+		{"gaiji", 1, s.Gaiji},
+		{"ruby", 1, s.Ruby},
+		{"ruby_chars", 3, s.RubyChars},
+	}
+	for _, tc := range tests {
+		if tc.value != tc.expect {
+			t.Errorf("%s: expect=%d actual=%d", tc.name, tc.expect, tc.value)
+		}
+	}
+	if s.Title != "茗荷畠" || s.Author != "眞山青果" {
+		t.Errorf("title and author: actual=%s %s", s.Title, s.Author)
+	}
+	if s.ReadingMinutes != 2 {
+		t.Errorf("reading minutes: expect=2 actual=%v", s.ReadingMinutes)
+	}
+	if s.RubyDensity != 0.25 {
+		t.Errorf("ruby density: expect=0.25 actual=%v", s.RubyDensity)
+	}
+	if len(s.TopChars) != 2 || s.TopChars[0].Char != "つ" || s.TopChars[1].Char != "で" {
+		t.Errorf("top chars: actual=%v", s.TopChars)
+	}
+}
@@ -39,3 +39,36 @@ func TestEncode1(t *testing.T) {
 		}
 	}
 }
+
+func TestJis2UniAfterGap(t *testing.T) {
+	tests := []struct {
+		men, ku, ten int
+		expect       string
+	}{
+		{1, 8, 62, "㊿"},
+		{1, 8, 92, "ǜ"},
+		{1, 12, 83, "㋬"},
+		{1, 12, 93, "⁑"},
+		{1, 12, 94, "⁂"},
+		{1, 13, 55, "Ⅻ"},
+		{1, 13, 63, "㍻"},
+		{1, 13, 64, "〝"},
+		{1, 13, 94, "☞"},
+	}
+	for _, tc := range tests {
+		actual, err := Jis2Uni(tc.men, tc.ku, tc.ten)
+		if err != nil {
+			t.Errorf("%d-%d-%d: no error: %+v", tc.men, tc.ku, tc.ten, err)
+			continue
+		}
+		if actual != tc.expect {
+			t.Errorf("%d-%d-%d: expect=%s actual=%s", tc.men, tc.ku, tc.ten, tc.expect, actual)
+		}
+	}
+	// unassigned cells in the gap
+	for _, ten := range []int{63, 70} {
+		if _, err := Jis2Uni(1, 8, ten); err == nil {
+			t.Errorf("1-8-%d: must error", ten)
+		}
+	}
+}
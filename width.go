@@ -0,0 +1,267 @@
+package aozoraconv
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	quotedTarget = regexp.MustCompile(`「([^」]*)」`)
+	widthExempt  = regexp.MustCompile(`^「(.+)」は(縦中横|横組み)$`)
+)
+
+// asciiFullWidth is full-width characters of ASCII which are not in
+// the simple offset (U+FF01-U+FF5E) or are preferred in JIS X 0208
+var asciiFullWidth = map[rune]rune{
+	' ':  '　',
+	'"':  '”',
+	'\'': '’',
+	'~':  '～',
+	'-':  '－',
+}
+
+// asciiKeep is ASCII characters that are not converted because the full-width
+// form is Aozora Bunko notation (｜ is start of ruby)
+const asciiKeep = "|"
+
+// halfWidthPunct is half-width katakana punctuation
+var halfWidthPunct = map[rune]rune{
+	'｡': '。',
+	'｢': '「',
+	'｣': '」',
+	'､': '、',
+	'･': '・',
+	'ﾞ': '゛',
+	'ﾟ': '゜',
+}
+
+// widthEscaper normalizes width of characters, half-width katakana is converted
+// into full-width (ｶﾞ -> ガ), ASCII is converted into full-width or full-width
+// alphanumerics are converted into ASCII if toHalf is true, texts in 縦中横 and
+// 横組み are not changed. the notation explanation of header (between separators)
+// and footer (from "底本：") are not changed, title and author before the separator
+// are converted as vertical text
+type widthEscaper struct {
+	toHalf      bool
+	yokogumi    int
+	separators  int
+	explanation bool
+	lines       int
+	empty       bool
+	blank       bool
+	body        bool
+	footer      bool
+}
+
+func (e *widthEscaper) Escape(src string) (string, bool) {
+	if e.skipLine(src) {
+		return src, true
+	}
+	exempt := widthExemptRanges(src)
+	buf := new(strings.Builder)
+	buf.Grow(len(src))
+	inRange := 0
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		if strings.HasPrefix(rest, notationGaijiOpen) {
+			if _, n, ok := annotationBody(rest[len(notationGaijiOpen):]); ok {
+				buf.WriteString(rest[:len(notationGaijiOpen)+n])
+				i += len(notationGaijiOpen) + n
+				continue
+			}
+		}
+		if strings.HasPrefix(rest, notationAnnotationOpen) {
+			if body, n, ok := annotationBody(rest[len(notationAnnotationOpen):]); ok {
+				inRange += e.updateRange(body)
+				buf.WriteString(notationAnnotationOpen)
+				buf.WriteString(e.annotation(body))
+				buf.WriteString(notationBracketClose)
+				i += len(notationAnnotationOpen) + n
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if 0 < inRange || 0 < e.yokogumi || exempt(i) {
+			buf.WriteString(rest[:size])
+			i += size
+			continue
+		}
+		if 0xff61 <= r && r <= 0xff9f {
+			s, n := fullWidthKatakana(rest)
+			buf.WriteString(s)
+			i += n
+			continue
+		}
+		buf.WriteRune(e.convert(r, buf.String()))
+		i += size
+	}
+	return buf.String(), true
+}
+
+// skipLine reports whether src is a line of header or footer, separators are the
+// header only before the body (title and author end at the first empty line),
+// after an empty line (or at the first line) and followed by the notation
+// explanation (【テキスト中に現れる記号について】)
+func (e *widthEscaper) skipLine(src string) bool {
+	if e.footer {
+		return true
+	}
+	line := strings.TrimRight(src, "\r\n")
+	if e.separators == 1 {
+		if line == notationSeparator {
+			e.separators = 2
+			e.body = true
+			return true
+		}
+		if e.explanation || strings.HasPrefix(line, "【") {
+			e.explanation = true
+			return true
+		}
+		// the separator was a rule in the body
+		e.separators = 0
+		e.body = true
+	}
+	if line == notationSeparator && e.body != true && (e.lines == 0 || e.empty) {
+		e.separators = 1
+		return true
+	}
+	if footerPattern.MatchString(src) {
+		e.footer = true
+		return true
+	}
+	switch {
+	case line == "":
+		e.blank = true
+	case e.blank:
+		e.body = true
+	}
+	e.lines += 1
+	e.empty = line == ""
+	return false
+}
+
+// updateRange returns +1 at start of 縦中横/横組み range and -1 at the end,
+// block of ここから横組み is kept over lines
+func (e *widthEscaper) updateRange(body string) int {
+	if m := layoutStart.FindStringSubmatch(body); m != nil && (m[2] == LayoutTatechuyoko || m[2] == LayoutYokogumi) {
+		if m[1] != "" {
+			e.yokogumi += 1
+			return 0
+		}
+		return 1
+	}
+	if m := layoutEnd.FindStringSubmatch(body); m != nil && (m[2] == LayoutTatechuyoko || m[2] == LayoutYokogumi) {
+		if m[1] != "" {
+			if 0 < e.yokogumi {
+				e.yokogumi -= 1
+			}
+			return 0
+		}
+		return -1
+	}
+	return 0
+}
+
+// annotation converts quoted targets in annotation body (［＃「ABC」に傍点］) as the text
+func (e *widthEscaper) annotation(body string) string {
+	if widthExempt.MatchString(body) || imageAnnotation.MatchString(body) {
+		return body
+	}
+	return quotedTarget.ReplaceAllStringFunc(body, func(m string) string {
+		runes := []rune(m)
+		for i, r := range runes {
+			runes[i] = e.convert(r, "")
+		}
+		return string(runes)
+	})
+}
+
+func (e *widthEscaper) convert(r rune, preceding string) rune {
+	if e.toHalf {
+		switch {
+		case '０' <= r && r <= '９', 'Ａ' <= r && r <= 'Ｚ', 'ａ' <= r && r <= 'ｚ':
+			return r - 0xfee0
+		}
+		return r
+	}
+
+	if r < 0x20 || 0x7e < r || strings.ContainsRune(asciiKeep, r) {
+		return r
+	}
+	// "[#" must not be an annotation
+	if r == '#' && strings.HasSuffix(preceding, notationBracketOpen) {
+		return r
+	}
+	if f, ok := asciiFullWidth[r]; ok {
+		return f
+	}
+	return r + 0xfee0
+}
+
+// widthExemptRanges returns function that reports whether byte index is in
+// the target of 縦中横/横組み reference annotations (10［＃「10」は縦中横］)
+func widthExemptRanges(src string) func(int) bool {
+	ranges := make([][2]int, 0, 2)
+	for i := 0; i < len(src); {
+		n := strings.Index(src[i:], notationAnnotationOpen)
+		if n < 0 {
+			break
+		}
+		start := i + n
+		body, size, ok := annotationBody(src[start+len(notationAnnotationOpen):])
+		if ok != true {
+			break
+		}
+		if m := widthExempt.FindStringSubmatch(body); m != nil && strings.HasSuffix(src[:start], m[1]) {
+			ranges = append(ranges, [2]int{start - len(m[1]), start})
+		}
+		i = start + len(notationAnnotationOpen) + size
+	}
+	return func(i int) bool {
+		for _, r := range ranges {
+			if r[0] <= i && i < r[1] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// fullWidthKatakana converts a half-width katakana at the head of s into
+// full-width joining following voiced sound mark, returns consumed bytes
+func fullWidthKatakana(s string) (string, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	if p, ok := halfWidthPunct[r]; ok {
+		return string(p), size
+	}
+	if next, n := utf8.DecodeRuneInString(s[size:]); next == 'ﾞ' || next == 'ﾟ' {
+		if joined := norm.NFKC.String(s[:size+n]); utf8.RuneCountInString(joined) == 1 {
+			return joined, size + n
+		}
+	}
+	return norm.NFKC.String(s[:size]), size
+}
+
+func newWidthEscaper(toHalf bool) *widthEscaper {
+	return &widthEscaper{
+		toHalf:      toHalf,
+		yokogumi:    0,
+		separators:  0,
+		explanation: false,
+		lines:       0,
+		empty:       false,
+		blank:       false,
+		body:        false,
+		footer:      false,
+	}
+}
+
+// NewWidthEscaper returns Escaper that normalizes width of characters,
+// ASCII into full-width or full-width alphanumerics into ASCII if toHalf is true
+func NewWidthEscaper(toHalf bool) Escaper {
+	return newWidthEscaper(toHalf)
+}
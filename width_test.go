@@ -0,0 +1,104 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWidthEscaper(t *testing.T) {
+	tests := []struct {
+		in     string
+		toHalf bool
+		expect string
+	}{
+		{"ABC abc 123!?\r\n", false, "ＡＢＣ　ａｂｃ　１２３！？\r\n"},
+		{"ｶﾞｲｼﾞとﾊﾟﾝ｡ｰﾞ", false, "ガイジとパン。ー゛"},
+		{"昭和10［＃「10」は縦中横］年のABC［＃「ABC」に傍点］", false, "昭和10［＃「10」は縦中横］年のＡＢＣ［＃「ＡＢＣ」に傍点］"},
+		{"［＃横組み］E=mc2［＃横組み終わり］と2", false, "［＃横組み］E=mc2［＃横組み終わり］と２"},
+		{"※［＃「木＋世」、U+67BB、13-3］[#]a|b", false, "※［＃「木＋世」、U+67BB、13-3］［#］ａ|ｂ"},
+		{"［＃挿絵（fig1234_01.png、横320×縦480）入る］", false, "［＃挿絵（fig1234_01.png、横320×縦480）入る］"},
+		{"ＡＢＣ　１２３！ｶﾞ", true, "ABC　123！ガ"},
+	}
+	for _, tc := range tests {
+		actual, ok := newWidthEscaper(tc.toHalf).Escape(tc.in)
+		if ok != true {
+			t.Errorf("always true")
+		}
+		if actual != tc.expect {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, tc.expect, actual)
+		}
+	}
+}
+
+func TestConvFullWidthBlock(t *testing.T) {
+	in := "A\r\n［＃ここから横組み］\r\nE=mc2\r\n［＃ここで横組み終わり］\r\nB\r\n"
+	out := bytes.NewBuffer(nil)
+	if err := Conv(out, strings.NewReader(in), WithFullWidth()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := "Ａ\r\n［＃ここから横組み］\r\nE=mc2\r\n［＃ここで横組み終わり］\r\nＢ\r\n"
+	if out.String() != expect {
+		t.Errorf("expect=%s actual=%s", expect, out.String())
+	}
+}
+
+func TestConvFullWidthHeaderFooter(t *testing.T) {
+	in := strings.Join([]string{
+		"ABC",
+		"",
+		"-------------------------------------------------------",
+		"【テキスト中に現れる記号について】",
+		"",
+		"《》：ルビ (ruby)",
+		"-------------------------------------------------------",
+		"E=mc2",
+		"",
+		"底本：「x」",
+		"（http://www.aozora.gr.jp/）",
+		"",
+	}, "\r\n")
+	out := bytes.NewBuffer(nil)
+	if err := Conv(out, strings.NewReader(in), WithFullWidth()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := strings.Replace(strings.Replace(in, "ABC", "ＡＢＣ", 1), "E=mc2", "Ｅ＝ｍｃ２", 1)
+	if out.String() != expect {
+		t.Errorf("expect=%s actual=%s", expect, out.String())
+	}
+}
+
+func TestConvFullWidthSeparatorInBody(t *testing.T) {
+	fullSeparator := strings.Repeat("－", len(notationSeparator))
+	tests := []struct {
+		in     []string
+		expect []string
+	}{
+		{
+			// no header
+			[]string{"ABC", "-------------------------------------------------------", "DEF", "GHI", ""},
+			[]string{"ＡＢＣ", fullSeparator, "ＤＥＦ", "ＧＨＩ", ""},
+		},
+		{
+			// rule after the title is not followed by the notation explanation
+			[]string{"ABC", "", "-------------------------------------------------------", "DEF", ""},
+			[]string{"ＡＢＣ", "", "-------------------------------------------------------", "ＤＥＦ", ""},
+		},
+		{
+			// rule after the body started
+			[]string{"ABC", "", "DEF", "-------------------------------------------------------", "【GHI】", ""},
+			[]string{"ＡＢＣ", "", "ＤＥＦ", fullSeparator, "【ＧＨＩ】", ""},
+		},
+	}
+	for _, tc := range tests {
+		in := strings.Join(tc.in, "\r\n")
+		out := bytes.NewBuffer(nil)
+		if err := Conv(out, strings.NewReader(in), WithFullWidth()); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		expect := strings.Join(tc.expect, "\r\n")
+		if out.String() != expect {
+			t.Errorf("%s: expect=%s actual=%s", in, expect, out.String())
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package aozoraconv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// WriteAozora writes document in Aozora Bunko format (Shift_JIS, CRLF),
+// characters out of JIS X 0208 in text are written in gaiji notation
+func WriteAozora(w io.Writer, doc *Document) error {
+	buf := new(strings.Builder)
+	for i, l := range doc.Lines {
+		writeAozoraLine(buf, l.Nodes, true)
+		// the last line keeps no line break of the source
+		if l.EOL != "" || i < len(doc.Lines)-1 {
+			buf.WriteString("\r\n")
+		}
+	}
+	if err := Encode(w, strings.NewReader(buf.String())); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// FormatLine returns a line in Aozora Bunko format (UTF-8), ruby index "｜" is written only if needed
+func FormatLine(l *Line) string {
+	buf := new(strings.Builder)
+	writeAozoraLine(buf, l.Nodes, false)
+	return buf.String() + l.EOL
+}
+
+// FormatNodes returns nodes in Aozora Bunko format (UTF-8)
+func FormatNodes(nodes []Node) string {
+	buf := new(strings.Builder)
+	writeAozoraNodes(buf, nodes, false, false)
+	return buf.String()
+}
+
+// notationCharGaiji is gaiji notation of characters used in notation,
+// "［" is written in gaiji notation only before "＃"
+var notationCharGaiji = map[rune]string{
+	'《': "※［＃始め二重山括弧、1-1-52］",
+	'》': "※［＃終わり二重山括弧、1-1-53］",
+	'｜': "※［＃縦線、1-1-35］",
+	'※': "※［＃米印、1-2-8］",
+	'［': "※［＃始め角括弧、1-1-46］",
+}
+
+// writeAozoraLine writes nodes of a line, characters of notation in text (《, ｜, ［＃)
+// are written in gaiji notation if the line would not be parsed into the same nodes
+func writeAozoraLine(buf *strings.Builder, nodes []Node, sjis bool) {
+	escape := reflect.DeepEqual(mergeText(nodes), ParseLine(FormatNodes(nodes)).Nodes) != true
+	writeAozoraNodes(buf, nodes, sjis, escape)
+}
+
+func writeAozoraNodes(buf *strings.Builder, nodes []Node, sjis, escape bool) {
+	for i, n := range nodes {
+		switch v := n.(type) {
+		case *Text:
+			if escape {
+				writeText(buf, escapeNotationChars(v.Value), sjis)
+			} else {
+				writeText(buf, v.Value, sjis)
+			}
+		case *Ruby:
+			if rubyIndexNeeded(nodes[:i], v.Base) {
+				buf.WriteString(notationRubyIndex)
+			}
+			writeAozoraNodes(buf, v.Base, sjis, escape)
+			buf.WriteString(notationRubyOpen)
+			if escape {
+				writeText(buf, escapeNotationChars(v.Reading), sjis)
+			} else {
+				writeText(buf, v.Reading, sjis)
+			}
+			buf.WriteString(notationRubyClose)
+		case *Gaiji:
+			if v.Description == "" && v.Note == "" && v.Char != "" {
+				buf.WriteString(gaijiNotation(v.Char))
+				continue
+			}
+			buf.WriteString(notationGaijiOpen + gaijiBodyString(v) + notationBracketClose)
+		case *Annotation:
+			writeAnnotation(buf, v.Body, sjis)
+		case *Image:
+			writeAnnotation(buf, imageBody(v), sjis)
+		case *PageBreak:
+			writeAnnotation(buf, v.Kind, sjis)
+		case *Kanbun:
+			if v.Okurigana != "" {
+				writeAnnotation(buf, "（"+v.Okurigana+"）", sjis)
+			} else {
+				writeAnnotation(buf, v.Kaeriten, sjis)
+			}
+		case *Layout:
+			if v.Reference {
+				writeAozoraNodes(buf, v.Nodes, sjis, escape)
+				writeAnnotation(buf, "「"+PlainText(v.Nodes)+"」は"+v.Kind, sjis)
+				continue
+			}
+			writeAnnotation(buf, v.Kind, sjis)
+			writeAozoraNodes(buf, v.Nodes, sjis, escape)
+			writeAnnotation(buf, v.Kind+"終わり", sjis)
+		case *LayoutStart:
+			if v.Block {
+				writeAnnotation(buf, "ここから"+v.Kind, sjis)
+			} else {
+				writeAnnotation(buf, v.Kind, sjis)
+			}
+		case *LayoutEnd:
+			if v.Block {
+				writeAnnotation(buf, "ここで"+v.Kind+"終わり", sjis)
+			} else {
+				writeAnnotation(buf, v.Kind+"終わり", sjis)
+			}
+		}
+	}
+}
+
+// writeAnnotation writes annotation, characters out of JIS X 0208 in body are written in gaiji notation if sjis
+func writeAnnotation(buf *strings.Builder, body string, sjis bool) {
+	buf.WriteString(notationAnnotationOpen)
+	writeText(buf, body, sjis)
+	buf.WriteString(notationBracketClose)
+}
+
+func writeText(buf *strings.Builder, text string, sjis bool) {
+	if sjis {
+		writeAozoraText(buf, text)
+	} else {
+		buf.WriteString(text)
+	}
+}
+
+// escapeNotationChars returns text that characters of notation are written in gaiji notation
+func escapeNotationChars(text string) string {
+	buf := new(strings.Builder)
+	buf.Grow(len(text))
+	for i, r := range text {
+		if g, ok := notationCharGaiji[r]; ok && (r != '［' || strings.HasPrefix(text[i+len("［"):], "＃")) {
+			buf.WriteString(g)
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// writeAozoraText writes text, characters out of JIS X 0208 and variation sequences are written in gaiji notation
+func writeAozoraText(buf *strings.Builder, text string) {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if vs, n := utf8.DecodeRuneInString(text[i+size:]); isVariationSelector(vs) {
+			buf.WriteString(gaijiNotation(text[i : i+size+n]))
+			i += size + n
+			continue
+		}
+		if isJIS0208(r) || isVariationSelector(r) {
+			buf.WriteString(text[i : i+size])
+		} else {
+			buf.WriteString(gaijiNotation(text[i : i+size]))
+		}
+		i += size
+	}
+}
+
+// gaijiNotation returns gaiji notation of a character or a variation sequence by men-ku-ten
+// of JIS X 0213 or code points (※［＃「〓」、第3水準1-14-2］, ※［＃「葛」、U+845B+E0101］),
+// the description is "〓" if the character can not be written in Shift_JIS
+func gaijiNotation(chr string) string {
+	r, size := utf8.DecodeRuneInString(chr)
+	description := string(r)
+	if isJIS0208(r) != true {
+		description = "〓"
+	}
+	if size == len(chr) {
+		if entry, ok := sjis2004Lookup(r); ok {
+			return entry.GaijiAnnotation(description)
+		}
+	}
+	if vs, n := utf8.DecodeRuneInString(chr[size:]); size+n == len(chr) {
+		if c, ok := variantIdeograph(r, vs); ok {
+			if entry, ok := lookupJis(c); ok {
+				return entry.GaijiAnnotation(description)
+			}
+		}
+	}
+	codes := make([]string, 0, 2)
+	for _, c := range chr {
+		codes = append(codes, fmt.Sprintf("%04X", c))
+	}
+	return fmt.Sprintf("%s「%s」、U+%s%s", notationGaijiOpen, description, strings.Join(codes, "+"), notationBracketClose)
+}
+
+// imageBody returns annotation body of image (「caption」のキャプション付きの挿絵（file、横W×縦H）入る)
+func imageBody(img *Image) string {
+	buf := new(strings.Builder)
+	if img.Caption != "" {
+		buf.WriteString("「" + img.Caption + "」のキャプション付きの")
+	}
+	buf.WriteString(img.Description + "（" + img.File)
+	if 0 < img.Width || 0 < img.Height {
+		fmt.Fprintf(buf, "、横%d×縦%d", img.Width, img.Height)
+	}
+	buf.WriteString("）入る")
+	return buf.String()
+}
+
+// rubyIndexNeeded reports whether base following preceding nodes is not an implicit ruby base
+func rubyIndexNeeded(preceding []Node, base []Node) bool {
+	nodes := make([]Node, 0, len(preceding)+len(base))
+	nodes = append(nodes, preceding...)
+	nodes = append(nodes, base...)
+	_, implicit := splitRubyBase(mergeText(nodes))
+	if len(implicit) < 1 {
+		return true
+	}
+	return FormatNodes(implicit) != FormatNodes(base)
+}
@@ -0,0 +1,315 @@
+package aozoraconv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteAozoraRoundTrip(t *testing.T) {
+	tests := []string{
+		strings.Join([]string{
+			"茗荷畠",
+			"眞山青果",
+			"",
+			"-------------------------------------------------------",
+			"【テキスト中に現れる記号について】",
+			"",
+			"《》：ルビ",
+			"（例）停車場《ステーション》",
+			"",
+			"｜：ルビの付く文字列の始まりを特定する記号",
+			"（例）晩｜停車場《ステーション》",
+			"",
+			"［＃］：入力者注　主に外字の説明や、傍点の位置の指定",
+			"-------------------------------------------------------",
+			"［＃７字下げ］一［＃「一」は中見出し］",
+			"下宿屋は蚊帳《かや》や蒲団《ふとん》を乾して居る",
+			"晩｜停車場《ステーション》で待つ［＃「待つ」に傍点］",
+			"カタカナ《かたかな》とＡＢＣ《えーびーしー》",
+			"その※［＃「足へん＋宛」、第3水準1-92-36］き《もがき》",
+			"諸※［＃「木＋世」、U+67BB、13-3］《もろもろ》",
+			"※［＃「てへん＋劣」、206-4］",
+			"［＃傍点］頭をフラ／＼［＃傍点終わり］と<振る>",
+			"［＃挿絵（fig1234_01.png、横320×縦480）入る］",
+			"［＃「停車場の図」のキャプション付きの図（fig1234_02.png、横200×縦100）入る］",
+			"［＃（fig1234_03.png）入る］",
+			"學而時習［＃レ］之［＃（ヲ）］",
+			"昭和10［＃「10」は縦中横］年",
+			"本文［＃割り注］注記｜東京《とうきょう》［＃割り注終わり］続き",
+			"［＃ここから横組み］",
+			"ｘ［＃上付き小文字］２［＃上付き小文字終わり］",
+			"［＃ここで横組み終わり］",
+			"［＃改ページ］",
+			"",
+			"底本：「真山青果全集」",
+			"",
+		}, "\r\n"),
+		strings.Join([]string{
+			"※［＃「木＋世」、U+67BB、13-3］と10［＃「10」は縦中横］の漢字",
+			"※［＃「足へん＋宛」、第3水準1-92-36］※［＃「てへん＋劣」、206-4］［＃「二」は中見出し］",
+			"あ［＃縦中横］12［＃縦中横終わり］ab",
+			"不［＃（ル）］［＃レ］知［＃一レ］［＃（ｘ）］",
+			"有［＃下］能［＃（ク）］治［＃（ムル）］［＃二］国［＃（ヲ）］［＃一］者［＃上］",
+			"前［＃改丁］後［＃改段］",
+			"東京《とうきょう》駅［＃「東京駅」は罫囲み］",
+			"駅前［＃「停車場」に傍点］［＃謎の指定］",
+			"昭和10［＃「10」は縦中横］年［＃割り注］注記［＃割り注終わり］Ｈ２［＃「２」は下付き小文字］Ｏ",
+			"［＃「石鏃」のキャプション付きの石鏃二つの図（fig42154_02.png）入る］",
+			"［＃ここから２字下げ］",
+			"［＃３字下げ］漢字《かんじ》の｜読み方《よみかた》",
+			"［＃ここで字下げ終わり］",
+			"※［＃「〓」、第3水準1-14-2］《※［＃「〓」、第3水準1-4-85］》",
+			"あ［＃「※［＃「〓」、第3水準1-14-2］」に傍点］",
+			"※［＃「〓」、第3水準1-14-2］［＃「※［＃「〓」、第3水準1-14-2］」は縦中横］",
+			"ABC［＃閉じていない",
+			"",
+		}, "\r\n"),
+		"終わりに改行のない行",
+		"",
+	}
+	for _, in := range tests {
+		expect := bytes.NewBuffer(nil)
+		if err := Encode(expect, strings.NewReader(in)); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		decoded := bytes.NewBuffer(nil)
+		if err := Decode(decoded, bytes.NewReader(expect.Bytes())); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		doc, err := Parse(decoded)
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		actual := bytes.NewBuffer(nil)
+		if err := WriteAozora(actual, doc); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		if bytes.Equal(expect.Bytes(), actual.Bytes()) != true {
+			t.Errorf("%s: expect=%x actual=%x", in, expect.Bytes(), actual.Bytes())
+		}
+	}
+}
+
+func TestFormatNodes(t *testing.T) {
+	tests := []struct {
+		nodes  []Node
+		expect string
+	}{
+		{
+			nodes: []Node{
+				&Text{"下宿屋は"},
+				&Ruby{Base: []Node{&Text{"蚊帳"}}, Reading: "かや", Explicit: true},
+			},
+			expect: "下宿屋は蚊帳《かや》",
+		},
+		{
+			nodes: []Node{
+				&Text{"晩"},
+				&Ruby{Base: []Node{&Text{"停車場"}}, Reading: "ステーション"},
+			},
+			expect: "晩｜停車場《ステーション》",
+		},
+		{
+			nodes: []Node{
+				&Ruby{Base: []Node{&Text{"青空文庫"}}, Reading: "あおぞらぶんこ"},
+				&Ruby{Base: []Node{&Text{"本"}}, Reading: "ほん"},
+			},
+			expect: "青空文庫《あおぞらぶんこ》本《ほん》",
+		},
+		{
+			nodes: []Node{
+				&Ruby{Base: []Node{&Text{"お茶"}}, Reading: "おちゃ"},
+			},
+			expect: "｜お茶《おちゃ》",
+		},
+		{
+			nodes: []Node{
+				&Text{"その"},
+				&Ruby{Base: []Node{&Text{"かな"}}, Reading: "カナ"},
+			},
+			expect: "その｜かな《カナ》",
+		},
+		{
+			nodes: []Node{
+				&Ruby{Base: []Node{
+					&Text{"諸"},
+					&Gaiji{Description: "木＋世", Note: "U+67BB、13-3", Char: "枻"},
+				}, Reading: "もろもろ"},
+			},
+			expect: "諸※［＃「木＋世」、U+67BB、13-3］《もろもろ》",
+		},
+		{
+			nodes: []Node{
+				&Gaiji{Char: "𠀋"},
+				&Gaiji{Char: "葛\U000E0101"},
+			},
+			expect: "※［＃「〓」、第3水準1-14-2］※［＃「葛」、U+845B+E0101］",
+		},
+		{
+			nodes: []Node{
+				&Image{Description: "挿絵", File: "fig1234_01.png", Width: 320, Height: 480, Caption: "停車場"},
+				&PageBreak{Kind: PageBreakRecto},
+			},
+			expect: "［＃「停車場」のキャプション付きの挿絵（fig1234_01.png、横320×縦480）入る］［＃改丁］",
+		},
+		{
+			nodes: []Node{
+				&Text{"學"},
+				&Kanbun{Okurigana: "ブ"},
+				&Kanbun{Kaeriten: "二"},
+			},
+			expect: "學［＃（ブ）］［＃二］",
+		},
+		{
+			nodes: []Node{
+				&Text{"昭和"},
+				&Layout{Kind: LayoutTatechuyoko, Nodes: []Node{&Text{"10"}}, Reference: true},
+				&Layout{Kind: LayoutWarichu, Nodes: []Node{&Text{"注記"}}},
+				&LayoutStart{Kind: LayoutYokogumi, Block: true},
+				&LayoutEnd{Kind: LayoutKogaki},
+			},
+			expect: "昭和10［＃「10」は縦中横］［＃割り注］注記［＃割り注終わり］［＃ここから横組み］［＃小書き終わり］",
+		},
+	}
+	for _, tc := range tests {
+		actual := FormatNodes(tc.nodes)
+		if tc.expect != actual {
+			t.Errorf("%s: expect=%s actual=%s", dumpNodes(tc.nodes), tc.expect, actual)
+		}
+	}
+}
+
+func TestWriteAozoraGaiji(t *testing.T) {
+	doc := &Document{Lines: []*Line{
+		{Nodes: []Node{&Text{"𠀋は外字"}}},
+		{Nodes: []Node{&Text{"葛\U000E0101飾"}}, EOL: "\r\n"},
+		{Nodes: []Node{&Text{"塚\uFE00"}}},
+	}}
+	out := bytes.NewBuffer(nil)
+	if err := WriteAozora(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	decoded := bytes.NewBuffer(nil)
+	if err := Decode(decoded, out); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := "※［＃「〓」、第3水準1-14-2］は外字\r\n※［＃「葛」、U+845B+E0101］飾\r\n※［＃「塚」、第3水準1-15-55］"
+	if decoded.String() != expect {
+		t.Errorf("expect=%s actual=%s", expect, decoded.String())
+	}
+}
+
+func TestWriteAozoraGaijiInNotation(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"𠀋《ゕ》", "※［＃「〓」、第3水準1-14-2］《※［＃「〓」、第3水準1-4-85］》"},
+		{"あ［＃「𠀋」に傍点］", "あ［＃「※［＃「〓」、第3水準1-14-2］」に傍点］"},
+		{"𠀋［＃「𠀋」は縦中横］", "※［＃「〓」、第3水準1-14-2］［＃「※［＃「〓」、第3水準1-14-2］」は縦中横］"},
+		{"［＃「𠀋の図」のキャプション付きの挿絵（fig1_01.png）入る］", "［＃「※［＃「〓」、第3水準1-14-2］の図」のキャプション付きの挿絵（fig1_01.png）入る］"},
+		{"本文［＃割り注］𠀋［＃割り注終わり］", "本文［＃割り注］※［＃「〓」、第3水準1-14-2］［＃割り注終わり］"},
+	}
+	for _, tc := range tests {
+		doc, err := Parse(strings.NewReader(tc.in))
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		out := bytes.NewBuffer(nil)
+		if err := WriteAozora(out, doc); err != nil {
+			t.Fatalf("%s: no error: %+v", tc.in, err)
+		}
+		decoded := bytes.NewBuffer(nil)
+		if err := Decode(decoded, bytes.NewReader(out.Bytes())); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		if decoded.String() != tc.expect {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, tc.expect, decoded.String())
+		}
+	}
+}
+
+func TestWriteAozoraNotationChars(t *testing.T) {
+	text := "記号《》と｜と※［＃x］"
+	doc := &Document{Lines: []*Line{{Nodes: []Node{&Text{text}}}}}
+	out := bytes.NewBuffer(nil)
+	if err := WriteAozora(out, doc); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	decoded := bytes.NewBuffer(nil)
+	if err := Decode(decoded, out); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	expect := "記号※［＃始め二重山括弧、1-1-52］※［＃終わり二重山括弧、1-1-53］と※［＃縦線、1-1-35］と※［＃米印、1-2-8］※［＃始め角括弧、1-1-46］＃x］"
+	if decoded.String() != expect {
+		t.Errorf("expect=%s actual=%s", expect, decoded.String())
+	}
+	if actual := PlainText(ParseLine(decoded.String()).Nodes); actual != text {
+		t.Errorf("expect=%s actual=%s", text, actual)
+	}
+}
+
+// writeParsed writes nodes by WriteAozora and parses it again
+func writeParsed(t *testing.T, nodes []Node) []Node {
+	t.Helper()
+	out := bytes.NewBuffer(nil)
+	if err := WriteAozora(out, &Document{Lines: []*Line{{Nodes: nodes}}}); err != nil {
+		t.Fatalf("%s: no error: %+v", dumpNodes(nodes), err)
+	}
+	decoded := bytes.NewBuffer(nil)
+	if err := Decode(decoded, out); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	return ParseLine(decoded.String()).Nodes
+}
+
+func TestWriteAozoraFixtures(t *testing.T) {
+	for _, tc := range parseLineTests {
+		if actual := writeParsed(t, tc.expect.Nodes); reflect.DeepEqual(tc.expect.Nodes, actual) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes(tc.expect.Nodes), dumpNodes(actual))
+		}
+	}
+	for _, tc := range parseImageTests {
+		if actual := writeParsed(t, []Node{tc.expect}); reflect.DeepEqual([]Node{tc.expect}, actual) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes([]Node{tc.expect}), dumpNodes(actual))
+		}
+	}
+	for _, tc := range parseLayoutTests {
+		if actual := writeParsed(t, tc.expect); reflect.DeepEqual(tc.expect, actual) != true {
+			t.Errorf("%s: expect=%s actual=%s", tc.in, dumpNodes(tc.expect), dumpNodes(actual))
+		}
+	}
+
+	for _, tt := range encodePairs {
+		doc, err := Parse(strings.NewReader(tt.in))
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		out := bytes.NewBuffer(nil)
+		if err := WriteAozora(out, doc); err != nil {
+			t.Fatalf("%s: no error: %+v", tt.in, err)
+		}
+		if bytes.Equal(tt.out, out.Bytes()) != true {
+			t.Errorf("%s: expect=%x actual=%x", tt.in, tt.out, out.Bytes())
+		}
+	}
+	for _, tt := range decodePairs {
+		decoded := bytes.NewBuffer(nil)
+		if err := Decode(decoded, bytes.NewReader(tt.in)); err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		doc, err := Parse(decoded)
+		if err != nil {
+			t.Fatalf("no error: %+v", err)
+		}
+		out := bytes.NewBuffer(nil)
+		if err := WriteAozora(out, doc); err != nil {
+			t.Fatalf("%s: no error: %+v", tt.out, err)
+		}
+		if bytes.Equal(tt.in, out.Bytes()) != true {
+			t.Errorf("%s: expect=%x actual=%x", tt.out, tt.in, out.Bytes())
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package aozoraconv
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// ImageSource opens image files referenced by illustration annotations
+type ImageSource interface {
+	OpenImage(name string) (io.ReadCloser, error)
+}
+
+var (
+	_ ImageSource = (*ZipArchive)(nil)
+	_ ImageSource = (*dirImageSource)(nil)
+)
+
+type dirImageSource struct {
+	dir string
+}
+
+func (s *dirImageSource) OpenImage(name string) (io.ReadCloser, error) {
+	clean := path.Clean("/" + filepath.ToSlash(name))
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(clean)))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return f, nil
+}
+
+// NewDirImageSource returns ImageSource that opens images in dir
+func NewDirImageSource(dir string) ImageSource {
+	return &dirImageSource{dir}
+}
+
+// ZipArchive is Aozora Bunko distribution archive (.zip) that contains
+// a Shift_JIS text file and illustration images
+type ZipArchive struct {
+	TextName string
+	Images   []string
+
+	closer io.Closer
+	text   *zip.File
+	images map[string]*zip.File
+}
+
+// OpenZip opens Aozora Bunko distribution archive
+func OpenZip(name string) (*ZipArchive, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	a, err := newZipArchive(&r.Reader)
+	if err != nil {
+		r.Close()
+		return nil, errors.WithStack(err)
+	}
+	a.closer = r
+	return a, nil
+}
+
+// NewZipArchive reads Aozora Bunko distribution archive from r
+func NewZipArchive(r io.ReaderAt, size int64) (*ZipArchive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newZipArchive(zr)
+}
+
+func newZipArchive(zr *zip.Reader) (*ZipArchive, error) {
+	names := make(map[*zip.File]string, len(zr.File))
+	texts := make([]*zip.File, 0, 1)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, err := zipFileName(f)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name, err = cleanZipName(name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names[f] = name
+		if strings.EqualFold(path.Ext(name), ".txt") {
+			texts = append(texts, f)
+		}
+	}
+	if len(texts) < 1 {
+		return nil, errors.Errorf("text file not found in archive")
+	}
+	sort.Slice(texts, func(i, j int) bool {
+		return names[texts[i]] < names[texts[j]]
+	})
+
+	text := texts[0]
+	textDir := path.Dir(names[text])
+	a := &ZipArchive{
+		TextName: names[text],
+		Images:   make([]string, 0, len(names)),
+		text:     text,
+		images:   make(map[string]*zip.File, len(names)),
+	}
+	for f, name := range names {
+		if f == text || isImageFile(name) != true {
+			continue
+		}
+		rel := name
+		if textDir != "." && strings.HasPrefix(name, textDir+"/") {
+			rel = strings.TrimPrefix(name, textDir+"/")
+		}
+		a.Images = append(a.Images, rel)
+		a.images[rel] = f
+	}
+	sort.Strings(a.Images)
+	return a, nil
+}
+
+// zipFileName returns file name, decoded from Shift_JIS unless UTF-8 flag is set
+func zipFileName(f *zip.File) (string, error) {
+	if f.Flags&0x800 != 0 || isASCII(f.Name) {
+		return f.Name, nil
+	}
+	name, err := japanese.ShiftJIS.NewDecoder().String(f.Name)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return name, nil
+}
+
+// cleanZipName returns cleaned name, absolute names and names which contain ".." are rejected
+func cleanZipName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	if path.IsAbs(slashed) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", errors.Errorf("absolute file name in archive: %s", name)
+	}
+	for _, seg := range strings.Split(slashed, "/") {
+		if seg == ".." {
+			return "", errors.Errorf("file name outside of archive: %s", name)
+		}
+	}
+	return path.Clean(slashed), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i += 1 {
+		if 0x80 <= s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isImageFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+		return true
+	}
+	return false
+}
+
+// OpenText opens the text file (Shift_JIS) in archive
+func (a *ZipArchive) OpenText() (io.ReadCloser, error) {
+	r, err := a.text.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return r, nil
+}
+
+// Decode converts the text file in archive into UTF-8
+func (a *ZipArchive) Decode(output io.Writer, opts ...OptionFunc) error {
+	r, err := a.OpenText()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	if err := Decode(output, r, opts...); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// OpenImage opens bundled image, name is relative to the text file
+func (a *ZipArchive) OpenImage(name string) (io.ReadCloser, error) {
+	f, ok := a.images[path.Clean(filepath.ToSlash(name))]
+	if ok != true {
+		return nil, errors.Errorf("image not found in archive: %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return r, nil
+}
+
+// Close closes archive opened by OpenZip
+func (a *ZipArchive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
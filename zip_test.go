@@ -0,0 +1,143 @@
+package aozoraconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createTestZip(t *testing.T, files map[string][]byte) *bytes.Reader {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(buf)
+	for name, data := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:    name,
+			Method:  zip.Deflate,
+			NonUTF8: true,
+		})
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestZipArchive(t *testing.T) {
+	r := createTestZip(t, map[string][]byte{
+		string(toSjis("みょうがばたけ/茗荷畠.txt")):     toSjis("田住生《たずみせい》\r\n"),
+		string(toSjis("みょうがばたけ/fig1_01.png")): {0x89, 0x50, 0x4e, 0x47},
+		"readme.html": []byte("<html></html>"),
+	})
+	a, err := NewZipArchive(r, r.Size())
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	defer a.Close()
+
+	if a.TextName != "みょうがばたけ/茗荷畠.txt" {
+		t.Errorf("text name: %s", a.TextName)
+	}
+	if len(a.Images) != 1 || a.Images[0] != "fig1_01.png" {
+		t.Errorf("images: %v", a.Images)
+	}
+
+	out := bytes.NewBuffer(nil)
+	if err := a.Decode(out, WithoutRuby()); err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if out.String() != "田住生\r\n" {
+		t.Errorf("actual=%s", out.String())
+	}
+
+	img, err := a.OpenImage("fig1_01.png")
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	defer img.Close()
+	data, err := io.ReadAll(img)
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if bytes.Equal(data, []byte{0x89, 0x50, 0x4e, 0x47}) != true {
+		t.Errorf("image: %v", data)
+	}
+	if _, err := a.OpenImage("fig1_02.png"); err == nil {
+		t.Errorf("should be error")
+	}
+}
+
+func TestZipArchiveNoText(t *testing.T) {
+	r := createTestZip(t, map[string][]byte{
+		"fig1_01.png": {0x89, 0x50, 0x4e, 0x47},
+	})
+	if _, err := NewZipArchive(r, r.Size()); err == nil {
+		t.Errorf("should be error")
+	}
+}
+
+func TestZipArchiveUnsafeName(t *testing.T) {
+	for _, name := range []string{"../../evil.png", "/evil.png", "sub/../../evil.png", "..\\evil.png"} {
+		r := createTestZip(t, map[string][]byte{
+			"a.txt": toSjis("あ\r\n"),
+			name:    {0x89, 0x50, 0x4e, 0x47},
+		})
+		if _, err := NewZipArchive(r, r.Size()); err == nil {
+			t.Errorf("%s: should be error", name)
+		}
+	}
+}
+
+func TestZipArchiveCleanName(t *testing.T) {
+	r := createTestZip(t, map[string][]byte{
+		"a.txt":       toSjis("あ\r\n"),
+		"sub/./x.png": {0x89, 0x50, 0x4e, 0x47},
+	})
+	a, err := NewZipArchive(r, r.Size())
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	if len(a.Images) != 1 || a.Images[0] != "sub/x.png" {
+		t.Errorf("images: %v", a.Images)
+	}
+	for _, name := range []string{"sub/x.png", "sub/./x.png"} {
+		img, err := a.OpenImage(name)
+		if err != nil {
+			t.Errorf("%s: no error: %+v", name, err)
+			continue
+		}
+		img.Close()
+	}
+}
+
+func TestDirImageSource(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "sub")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fig1_01.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "outside.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	s := NewDirImageSource(dir)
+	img, err := s.OpenImage("fig1_01.png")
+	if err != nil {
+		t.Fatalf("no error: %+v", err)
+	}
+	img.Close()
+	if _, err := s.OpenImage("../outside.png"); err == nil {
+		t.Errorf("should not open outside of dir")
+	}
+}